@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/urfave/cli/v2"
+)
+
+var cleanupCMD = &cli.Command{
+	Name:  "cleanup",
+	Usage: "log out the governed objects a run registered",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "run",
+			Usage: "Specify the run to clean up, the recorded runs are listed if omitted",
+		},
+		&cli.StringFlag{
+			Name:    "remote_bitxhub_addr",
+			Aliases: []string{"r"},
+			Usage:   "Specify remote bitxhub address",
+			Value:   "localhost:60011",
+		},
+	},
+	Action: cleanup,
+}
+
+func cleanup(ctx *cli.Context) error {
+	id := ctx.String("run")
+	if id == "" {
+		return listRuns()
+	}
+	run, err := manifest.Load(id)
+	if err != nil {
+		return err
+	}
+	summaries, err := manifest.Cleanup(run, ctx.String("remote_bitxhub_addr"))
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, s := range summaries {
+		fmt.Printf("%-10s logged out %d, skipped %d, failed %d\n", s.Kind, s.LoggedOut, s.Skipped, s.Failed)
+		failed += s.Failed
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d objects of run %s are not logged out, run the cleanup again", failed, id)
+	}
+	return nil
+}
+
+func listRuns() error {
+	ids, err := manifest.List()
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Println("no run recorded")
+		return nil
+	}
+	for _, id := range ids {
+		run, err := manifest.Load(id)
+		if err != nil {
+			fmt.Printf("%s: %s\n", id, err)
+			continue
+		}
+		fmt.Printf("%s: %d objects\n", id, len(run.Entries))
+	}
+	return nil
+}
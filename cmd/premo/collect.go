@@ -0,0 +1,57 @@
+package main
+
+import (
+	"time"
+
+	"github.com/meshplus/premo/internal/tps"
+	"github.com/urfave/cli/v2"
+)
+
+// collectFlags are the flags of the tps collection shared by test, sweep and evm
+var collectFlags = []cli.Flag{
+	&cli.IntFlag{
+		Name:  "tps_warmup",
+		Value: 5,
+		Usage: "seconds of blocks excluded from the start of the run when averaging the tps",
+	},
+	&cli.IntFlag{
+		Name:  "tps_cooldown",
+		Value: 5,
+		Usage: "seconds of blocks excluded from the end of the run when averaging the tps",
+	},
+	&cli.Uint64Flag{
+		Name:  "tps_warmup_blocks",
+		Usage: "blocks excluded from the start of the run when averaging the tps",
+	},
+	&cli.Uint64Flag{
+		Name:  "tps_cooldown_blocks",
+		Usage: "blocks excluded from the end of the run when averaging the tps",
+	},
+	&cli.Uint64Flag{
+		Name:  "tps_window",
+		Value: 100,
+		Usage: "blocks per tps window, the report gives the mean and stddev across windows",
+	},
+	&cli.IntFlag{
+		Name:  "drain_quiet",
+		Value: 5,
+		Usage: "seconds the chain height must stay the same for the sent txs to be drained",
+	},
+	&cli.IntFlag{
+		Name:  "drain_timeout",
+		Value: 120,
+		Usage: "maximum seconds to wait for the sent txs to drain",
+	},
+}
+
+func newCollectConfig(ctx *cli.Context) tps.Config {
+	return tps.Config{
+		Warmup:         time.Duration(ctx.Int("tps_warmup")) * time.Second,
+		Cooldown:       time.Duration(ctx.Int("tps_cooldown")) * time.Second,
+		WarmupBlocks:   ctx.Uint64("tps_warmup_blocks"),
+		CooldownBlocks: ctx.Uint64("tps_cooldown_blocks"),
+		Window:         ctx.Uint64("tps_window"),
+		Quiet:          time.Duration(ctx.Int("drain_quiet")) * time.Second,
+		DrainTimeout:   time.Duration(ctx.Int("drain_timeout")) * time.Second,
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/meshplus/premo/internal/doctor"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/urfave/cli/v2"
+)
+
+var doctorCMD = &cli.Command{
+	Name:  "doctor",
+	Usage: "check the environment a benchmark needs and print how to fix what is wrong",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:    "concurrent",
+			Aliases: []string{"c"},
+			Value:   100,
+			Usage:   "concurrent number the admin balance must fund",
+		},
+		&cli.StringFlag{
+			Name:    "key_path",
+			Aliases: []string{"k"},
+			Usage:   "Specify key path",
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "Specify tx type: interchain, data, transfer, rollback",
+			Value: "transfer",
+		},
+		&cli.StringSliceFlag{
+			Name:    "remote_bitxhub_addr",
+			Aliases: []string{"r"},
+			Usage:   "Specify remote bitxhub address",
+			Value:   cli.NewStringSlice("localhost:60011"),
+		},
+		&cli.StringFlag{
+			Name:  "appchain",
+			Usage: "Specify appchain profile: fabric, flato, eth, hyperchain, bcos or one in appchains.json",
+			Value: "flato",
+		},
+		&cli.BoolFlag{
+			Name:    "multiDestChain",
+			Usage:   "Specify different src annchain send tx to different dest appchain",
+			Aliases: []string{"m"},
+			Value:   false,
+		},
+		&cli.StringFlag{
+			Name:  "crypto",
+			Usage: "Specify the crypto algorithm of the generated accounts",
+			Value: "Secp256k1",
+		},
+	},
+	Action: doctorCheck,
+}
+
+func doctorCheck(ctx *cli.Context) error {
+	if err := repo.SetKeyType(ctx.String("crypto")); err != nil {
+		return err
+	}
+	keyPath := ctx.String("key_path")
+	if keyPath == "" {
+		//default use node4
+		var err error
+		keyPath, err = repo.Node4Path()
+		if err != nil {
+			return err
+		}
+	}
+	results := doctor.Run(&doctor.Config{
+		KeyPath:        keyPath,
+		BitxhubAddr:    ctx.StringSlice("remote_bitxhub_addr"),
+		Concurrent:     ctx.Int("concurrent"),
+		Type:           ctx.String("type"),
+		MultiDestChain: ctx.Bool("multiDestChain"),
+		Appchain:       ctx.String("appchain"),
+	})
+	doctor.Print(os.Stdout, results)
+	if doctor.Failed(results) {
+		return fmt.Errorf("doctor found problems, fix them before running a benchmark")
+	}
+	return nil
+}
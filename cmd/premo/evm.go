@@ -16,7 +16,7 @@ import (
 var evmCMD = &cli.Command{
 	Name:  "evm",
 	Usage: "test bitxhub evm function",
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.IntFlag{
 			Name:    "concurrent",
 			Aliases: []string{"c"},
@@ -35,6 +35,10 @@ var evmCMD = &cli.Command{
 			Value:   60,
 			Usage:   "test duration",
 		},
+		&cli.IntFlag{
+			Name:  "clock_warmup",
+			Usage: "seconds to estimate the clock offset to bitxhub nodes, latencies are not measured meanwhile, 0 disables it",
+		},
 		&cli.StringFlag{
 			Name:  "contract_path",
 			Usage: "Specify contract path",
@@ -68,7 +72,7 @@ var evmCMD = &cli.Command{
 			Aliases: []string{"a"},
 			Usage:   "Specify args(both deploy and invoke)",
 		},
-	},
+	}, collectFlags...),
 	Action: evmBenchmark,
 }
 
@@ -108,6 +112,8 @@ func evmBenchmark(ctx *cli.Context) error {
 		KeyPath:      keyPath,
 		JsonRpc:      "http://" + addr,
 		Grpc:         grpc,
+		ClockWarmup:  ctx.Int("clock_warmup"),
+		Collect:      newCollectConfig(ctx),
 		Ctx:          c,
 		CancelFunc:   cancelFunc,
 	}
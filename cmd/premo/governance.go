@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/meshplus/premo/internal/bitxhub"
+	"github.com/meshplus/premo/internal/governance"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/urfave/cli/v2"
+)
+
+// governanceBenchmark runs the governance workload, the tx assertions of a benchmark do not apply to it
+func governanceBenchmark(ctx *cli.Context, scenario *Scenario) error {
+	if scenario.assertions(ctx).Enabled() {
+		return fmt.Errorf("assertions check txs, they do not apply to the %s workload", governance.Type)
+	}
+	config, err := newBenchmarkConfig(ctx, scenario)
+	if err != nil {
+		return err
+	}
+	targets, err := governance.ParseTargets(scenario.stringValue(ctx, "governance_targets", scenario.GovernanceTargets))
+	if err != nil {
+		return err
+	}
+	if err := repo.SetKeyType(config.Crypto); err != nil {
+		return err
+	}
+	g, err := governance.New(newGovernanceConfig(config, targets))
+	if err != nil {
+		return err
+	}
+
+	release := handleStop(g.Stop)
+	defer release()
+
+	return g.Start()
+}
+
+func newGovernanceConfig(config *bitxhub.Config, targets []string) *governance.Config {
+	return &governance.Config{
+		Concurrent:  config.Concurrent,
+		Duration:    config.Duration,
+		Targets:     targets,
+		KeyPath:     config.KeyPath,
+		BitxhubAddr: config.BitxhubAddr,
+		Appchain:    config.Appchain,
+		TrustRoot:   []byte(config.Validator),
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/meshplus/premo/internal/preload"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/urfave/cli/v2"
+)
+
+var preloadCMD = &cli.Command{
+	Name:  "preload",
+	Usage: "create accounts and store keys to grow the bitxhub state before a benchmark",
+	Flags: []cli.Flag{
+		&cli.Uint64Flag{
+			Name:  "accounts",
+			Usage: "Specify the number of accounts to create",
+		},
+		&cli.Uint64Flag{
+			Name:  "keys",
+			Usage: "Specify the number of keys to set in the store contract",
+		},
+		&cli.IntFlag{
+			Name:  "value_size",
+			Value: 32,
+			Usage: "Specify the size in bytes of the value of every key",
+		},
+		&cli.StringFlag{
+			Name:  "balance",
+			Value: "1",
+			Usage: "Specify the balance, in the smallest unit, of every account",
+		},
+		&cli.IntFlag{
+			Name:    "concurrent",
+			Aliases: []string{"c"},
+			Value:   50,
+			Usage:   "Specify the number of sender accounts",
+		},
+		&cli.IntFlag{
+			Name:  "batch_size",
+			Value: preload.DefaultBatchSize,
+			Usage: "Specify the txs sent by one SendTransactions",
+		},
+		&cli.StringFlag{
+			Name:  "resume",
+			Usage: "Specify the preload to resume, its accounts, keys, value size, balance and batch size are kept",
+		},
+		&cli.StringFlag{
+			Name:    "key_path",
+			Aliases: []string{"k"},
+			Usage:   "Specify key path of the account funding the senders",
+		},
+		&cli.StringSliceFlag{
+			Name:    "remote_bitxhub_addr",
+			Aliases: []string{"r"},
+			Usage:   "Specify remote bitxhub address",
+			Value:   cli.NewStringSlice("localhost:60011"),
+		},
+		&cli.StringFlag{
+			Name:  "crypto",
+			Usage: "Specify algorithm of sender accounts: Secp256k1, SM2, ECDSA-P256",
+			Value: "Secp256k1",
+		},
+	},
+	Action: preloadState,
+}
+
+func preloadState(ctx *cli.Context) error {
+	if err := repo.SetKeyType(ctx.String("crypto")); err != nil {
+		return err
+	}
+	keyPath := ctx.String("key_path")
+	if keyPath == "" {
+		var err error
+		keyPath, err = repo.Node4Path()
+		if err != nil {
+			return err
+		}
+	}
+	p, err := preload.New(&preload.Config{
+		Resume:      ctx.String("resume"),
+		Accounts:    ctx.Uint64("accounts"),
+		Keys:        ctx.Uint64("keys"),
+		ValueSize:   ctx.Int("value_size"),
+		Balance:     ctx.String("balance"),
+		BatchSize:   ctx.Int("batch_size"),
+		Concurrent:  ctx.Int("concurrent"),
+		KeyPath:     keyPath,
+		BitxhubAddr: ctx.StringSlice("remote_bitxhub_addr"),
+	})
+	if err != nil {
+		return err
+	}
+
+	var stop = make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM)
+	signal.Notify(stop, syscall.SIGINT)
+	defer signal.Stop(stop)
+	go func() {
+		if _, ok := <-stop; ok {
+			fmt.Printf("received interrupt signal, saving the progress of preload %s...\n", p.ID())
+			p.Stop()
+		}
+	}()
+	return p.Start()
+}
@@ -34,6 +34,11 @@ func main() {
 		bitxhubCMD,
 		serverCMD,
 		evmCMD,
+		sweepCMD,
+		subscribeCMD,
+		doctorCMD,
+		cleanupCMD,
+		preloadCMD,
 	}
 
 	err := app.Run(os.Args)
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/meshplus/premo/internal/query"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/urfave/cli/v2"
+)
+
+// queryBenchmark runs the query workload, the tx assertions of a benchmark do not apply to it
+func queryBenchmark(ctx *cli.Context, scenario *Scenario) error {
+	if scenario.assertions(ctx).Enabled() {
+		return fmt.Errorf("assertions check txs, they do not apply to the %s workload", query.Type)
+	}
+	mix, err := query.ParseMix(scenario.stringValue(ctx, "query_mix", scenario.QueryMix))
+	if err != nil {
+		return err
+	}
+	if err := repo.SetKeyType(ctx.String("crypto")); err != nil {
+		return err
+	}
+	q, err := query.New(&query.Config{
+		Concurrent:  scenario.intValue(ctx, "concurrent", scenario.Concurrent),
+		Duration:    scenario.intValue(ctx, "duration", scenario.Duration),
+		Mix:         mix,
+		BlocksRange: ctx.Int("query_blocks_range"),
+		BitxhubAddr: ctx.StringSlice("remote_bitxhub_addr"),
+	})
+	if err != nil {
+		return err
+	}
+
+	release := handleStop(q.Stop)
+	defer release()
+
+	return q.Start()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/meshplus/premo/internal/bitxhub"
+	"github.com/urfave/cli/v2"
+)
+
+// Scenario describes a benchmark in a json file, flags set on the command line take precedence
+type Scenario struct {
+	Concurrent        int                 `json:"concurrent"`
+	TPS               int                 `json:"tps"`
+	Duration          int                 `json:"duration"`
+	Type              string              `json:"type"`
+	PayloadSize       int                 `json:"payload_size"`
+	Arrival           string              `json:"arrival"`
+	BatchSize         int                 `json:"batch_size"`
+	Appchain          string              `json:"appchain"`
+	Validator         string              `json:"validator"` // trust root file of the appchain
+	Proof             string              `json:"proof"`     // proof file or directory of proof files
+	SoakInterval      int                 `json:"soak_interval"`
+	SoakOutput        string              `json:"soak_output"`
+	StatusSample      int                 `json:"status_sample"`
+	Total             int                 `json:"total"`
+	GovernanceTargets string              `json:"governance_targets"`
+	QueryMix          string              `json:"query_mix"`
+	Assertions        *ScenarioAssertions `json:"assertions"`
+}
+
+// ScenarioAssertions are the assertions evaluated at the end of a benchmark
+type ScenarioAssertions struct {
+	MinTPS          *float64 `json:"min_tps"`
+	MaxP99Latency   *int64   `json:"max_p99_latency"` // ms
+	MaxFailureRatio *float64 `json:"max_failure_ratio"`
+	MaxMissing      *int64   `json:"max_missing"`
+}
+
+func loadScenario(path string) (*Scenario, error) {
+	scenario := &Scenario{}
+	if path == "" {
+		return scenario, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read scenario file: %w", err)
+	}
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("unmarshal scenario file: %w", err)
+	}
+	return scenario, nil
+}
+
+func (s *Scenario) intValue(ctx *cli.Context, name string, value int) int {
+	if ctx.IsSet(name) || value == 0 {
+		return ctx.Int(name)
+	}
+	return value
+}
+
+func (s *Scenario) stringValue(ctx *cli.Context, name string, value string) string {
+	if ctx.IsSet(name) || value == "" {
+		return ctx.String(name)
+	}
+	return value
+}
+
+// assertions merges the assertions from the scenario file and the command line
+func (s *Scenario) assertions(ctx *cli.Context) *bitxhub.Assertions {
+	a := bitxhub.NewAssertions()
+	if s.Assertions != nil {
+		if s.Assertions.MinTPS != nil {
+			a.MinTPS = *s.Assertions.MinTPS
+		}
+		if s.Assertions.MaxP99Latency != nil {
+			a.MaxP99Latency = time.Duration(*s.Assertions.MaxP99Latency) * time.Millisecond
+		}
+		if s.Assertions.MaxFailureRatio != nil {
+			a.MaxFailureRatio = *s.Assertions.MaxFailureRatio
+		}
+		if s.Assertions.MaxMissing != nil {
+			a.MaxMissing = *s.Assertions.MaxMissing
+		}
+	}
+	if ctx.IsSet("min_tps") {
+		a.MinTPS = ctx.Float64("min_tps")
+	}
+	if ctx.IsSet("max_p99_latency") {
+		a.MaxP99Latency = time.Duration(ctx.Int64("max_p99_latency")) * time.Millisecond
+	}
+	if ctx.IsSet("max_failure_ratio") {
+		a.MaxFailureRatio = ctx.Float64("max_failure_ratio")
+	}
+	if ctx.IsSet("max_missing") {
+		a.MaxMissing = ctx.Int64("max_missing")
+	}
+	return a
+}
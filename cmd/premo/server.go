@@ -1,7 +1,10 @@
 package main
 
 import (
+	"github.com/meshplus/premo/internal/appchain"
+	"github.com/meshplus/premo/internal/repo"
 	"github.com/meshplus/premo/internal/server"
+	"github.com/meshplus/premo/internal/service"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,6 +30,21 @@ var serverCMD = &cli.Command{
 			Usage:   "Specify server's pool size",
 			Value:   10,
 		},
+		&cli.StringFlag{
+			Name:  "appchain",
+			Usage: "Specify appchain profile registered by the server: fabric_mock or one in appchains.json",
+			Value: "fabric_mock",
+		},
+		&cli.StringFlag{
+			Name:  "crypto",
+			Usage: "Specify algorithm of the accounts sending txs: Secp256k1, SM2, ECDSA-P256",
+			Value: "Secp256k1",
+		},
+		&cli.StringFlag{
+			Name:  "bxh_id",
+			Usage: "Specify chain ID of the relay chain in full service IDs",
+			Value: service.DefaultBxhID,
+		},
 	},
 	Action: serverBenchmark,
 }
@@ -35,7 +53,14 @@ func serverBenchmark(ctx *cli.Context) error {
 	remote := ctx.String("remote_bitxhub_addr")
 	port := ctx.Int("port")
 	poolSize := ctx.Int("pool_size")
-	newServer, err := server.NewServer(remote, port, poolSize)
+	if err := repo.SetKeyType(ctx.String("crypto")); err != nil {
+		return err
+	}
+	profile, err := appchain.Lookup(ctx.String("appchain"))
+	if err != nil {
+		return err
+	}
+	newServer, err := server.NewServer(remote, port, poolSize, profile, ctx.String("bxh_id"))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/subscribe"
+	"github.com/urfave/cli/v2"
+)
+
+var subscribeCMD = &cli.Command{
+	Name:  "subscribe",
+	Usage: "test bitxhub subscription fan-out while another workload produces traffic",
+	Flags: []cli.Flag{
+		&cli.IntSliceFlag{
+			Name:    "subscribers",
+			Aliases: []string{"n"},
+			Usage:   "Specify the subscriber count of every step, e.g. 10,100,500",
+			Value:   cli.NewIntSlice(10),
+		},
+		&cli.StringFlag{
+			Name:  "types",
+			Usage: "Specify the comma separated subscription types the subscribers take in turn: block, block_header, interchain_tx_wrapper, union_interchain_tx_wrapper, audit_node",
+			Value: "block,block_header",
+		},
+		&cli.IntFlag{
+			Name:    "duration",
+			Aliases: []string{"d"},
+			Value:   60,
+			Usage:   "Specify the duration of every step",
+		},
+		&cli.StringSliceFlag{
+			Name:    "remote_bitxhub_addr",
+			Aliases: []string{"r"},
+			Usage:   "Specify remote bitxhub address, the subscribers are spread over them",
+			Value:   cli.NewStringSlice("localhost:60011"),
+		},
+		&cli.StringFlag{
+			Name:  "extra",
+			Usage: "Specify the extra of interchain_tx_wrapper and union_interchain_tx_wrapper subscriptions",
+		},
+		&cli.StringSliceFlag{
+			Name:  "audit_permit",
+			Usage: "Specify the appchains audited by audit_node subscribers, an nvp node permitted to audit them is registered for the run",
+		},
+		&cli.StringSliceFlag{
+			Name:  "metrics_addr",
+			Usage: "Specify the prometheus endpoints of the nodes to report their cpu, e.g. localhost:40011/metrics",
+		},
+		&cli.IntFlag{
+			Name:  "clock_warmup",
+			Usage: "seconds to estimate the clock offset to bitxhub nodes, latencies are not measured meanwhile, 0 disables it",
+		},
+		&cli.StringFlag{
+			Name:  "crypto",
+			Usage: "Specify the crypto algorithm of the generated accounts",
+			Value: "Secp256k1",
+		},
+	},
+	Action: subscribeBenchmark,
+}
+
+func subscribeBenchmark(ctx *cli.Context) error {
+	types, err := subscribe.ParseTypes(ctx.String("types"))
+	if err != nil {
+		return err
+	}
+	if err := repo.SetKeyType(ctx.String("crypto")); err != nil {
+		return err
+	}
+	f, err := subscribe.New(&subscribe.Config{
+		Subscribers: ctx.IntSlice("subscribers"),
+		Types:       types,
+		Duration:    ctx.Int("duration"),
+		BitxhubAddr: ctx.StringSlice("remote_bitxhub_addr"),
+		Extra:       ctx.String("extra"),
+		AuditPermit: ctx.StringSlice("audit_permit"),
+		MetricsAddr: ctx.StringSlice("metrics_addr"),
+		ClockWarmup: ctx.Int("clock_warmup"),
+	})
+	if err != nil {
+		return err
+	}
+
+	release := handleStop(f.Stop)
+	defer release()
+
+	return f.Start()
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meshplus/premo/internal/bitxhub"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/urfave/cli/v2"
+)
+
+var sweepCMD = &cli.Command{
+	Name:  "sweep",
+	Usage: "run test bitxhub function over a range of parameters",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:    "concurrent",
+			Aliases: []string{"c"},
+			Value:   "100",
+			Usage:   "concurrent numbers, a list like 10,50 or a range like 10:100:10",
+		},
+		&cli.StringFlag{
+			Name:    "tps",
+			Aliases: []string{"t"},
+			Value:   "500",
+			Usage:   "all tx numbers, a list or a range",
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Value: "transfer",
+			Usage: "tx types, a list of interchain, data, transfer, rollback",
+		},
+		&cli.StringFlag{
+			Name:  "payload_size",
+			Value: "0",
+			Usage: "sizes in bytes of the value stored by data tx, a list or a range",
+		},
+		&cli.StringFlag{
+			Name:  "nodes",
+			Value: "1",
+			Usage: "numbers of bitxhub nodes the bees send to, a list or a range",
+		},
+		&cli.StringFlag{
+			Name:  "crypto",
+			Value: "Secp256k1",
+			Usage: "algorithms of bee accounts, a list of Secp256k1, SM2, ECDSA-P256",
+		},
+		&cli.StringFlag{
+			Name:  "runs",
+			Usage: "Specify a json file listing the combinations to run instead of the cartesian product",
+		},
+		&cli.IntFlag{
+			Name:    "duration",
+			Aliases: []string{"d"},
+			Value:   60,
+			Usage:   "test duration of every run",
+		},
+		&cli.IntFlag{
+			Name:  "cool_down",
+			Value: 10,
+			Usage: "seconds to wait between runs",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   "sweep",
+			Usage:   "Specify the directory of comparison.csv and comparison.html",
+		},
+	}, append(sendFlags, collectFlags...)...),
+	Action: sweep,
+}
+
+// sweepTypes are the tx types a sweep run can take
+var sweepTypes = []string{bitxhub.Interchain, bitxhub.Data, bitxhub.Transfer, bitxhub.Rollback}
+
+// sweepRun is one combination of a sweep
+type sweepRun struct {
+	Concurrent  int    `json:"concurrent"`
+	TPS         int    `json:"tps"`
+	Type        string `json:"type"`
+	PayloadSize int    `json:"payload_size"`
+	Nodes       int    `json:"nodes"`
+	Crypto      string `json:"crypto"`
+}
+
+func sweep(ctx *cli.Context) error {
+	runs, err := sweepRuns(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkSweepRuns(runs); err != nil {
+		return err
+	}
+	base, err := newBenchmarkConfig(ctx, &Scenario{})
+	if err != nil {
+		return err
+	}
+	addrs := base.BitxhubAddr
+	coolDown := time.Duration(ctx.Int("cool_down")) * time.Second
+	stopper := newSweepStopper()
+	release := handleStop(stopper.stop)
+	defer release()
+
+	results := make([]*bitxhub.SweepResult, 0, len(runs))
+	for i, run := range runs {
+		result := &bitxhub.SweepResult{
+			Concurrent:  run.Concurrent,
+			TPS:         run.TPS,
+			Type:        run.Type,
+			PayloadSize: run.PayloadSize,
+			Nodes:       run.Nodes,
+			Crypto:      run.Crypto,
+		}
+		results = append(results, result)
+		fmt.Printf("sweep run %d/%d: %s\n", i+1, len(runs), result.Label())
+
+		if run.Nodes < 1 || run.Nodes > len(addrs) {
+			result.Err = fmt.Errorf("nodes should be between 1 and %d", len(addrs))
+			continue
+		}
+		config := *base
+		config.Concurrent = run.Concurrent
+		config.TPS = run.TPS
+		config.Type = run.Type
+		config.PayloadSize = run.PayloadSize
+		config.BitxhubAddr = addrs[:run.Nodes]
+		config.Crypto = run.Crypto
+		result.Report, result.Err = stopper.run(&config)
+		if result.Err != nil {
+			fmt.Printf("sweep run %d failed: %s\n", i+1, result.Err)
+		}
+
+		if i == len(runs)-1 || stopper.stopped() {
+			break
+		}
+		if err := bitxhub.WaitChainDrain(addrs[0], &base.Collect); err != nil {
+			fmt.Printf("wait chain drain: %s\n", err)
+		}
+		select {
+		case <-stopper.done:
+		case <-time.After(coolDown):
+		}
+		if stopper.stopped() {
+			break
+		}
+	}
+
+	if err := bitxhub.WriteComparison(ctx.String("output"), results); err != nil {
+		return err
+	}
+	if stopper.stopped() {
+		return cli.Exit(fmt.Sprintf("sweep interrupted at run %d of %d", len(results), len(runs)), bitxhub.ExitNoReport)
+	}
+	return nil
+}
+
+// errSweepInterrupted is the error of the run an interrupt signal stopped
+var errSweepInterrupted = errors.New("interrupted")
+
+// sweepStopper stops the current run of a sweep on interrupt signal, the sweep then
+// starts no other run and writes the comparison of the runs done
+type sweepStopper struct {
+	lock    sync.Mutex
+	current *bitxhub.Broker
+	started time.Time
+	done    chan struct{}
+}
+
+func newSweepStopper() *sweepStopper {
+	return &sweepStopper{done: make(chan struct{})}
+}
+
+// run runs the benchmark of config unless the sweep is stopped
+func (s *sweepStopper) run(config *bitxhub.Config) (*bitxhub.Report, error) {
+	if s.stopped() {
+		return nil, errSweepInterrupted
+	}
+	broker, err := newBroker(config)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	if s.stopped() {
+		s.lock.Unlock()
+		// release the accounts and the manifest the broker prepared
+		_ = broker.Stop(time.Now())
+		return nil, errSweepInterrupted
+	}
+	s.current, s.started = broker, time.Now()
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		s.current = nil
+		s.lock.Unlock()
+	}()
+
+	if err := broker.Start(); err != nil {
+		return nil, err
+	}
+	if report := broker.Report(); report != nil {
+		return report, nil
+	}
+	return nil, errSweepInterrupted
+}
+
+func (s *sweepStopper) stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	close(s.done)
+	if s.current != nil {
+		// Stop blocks while the broker collects the tps, the run returns once it is done
+		go func(broker *bitxhub.Broker, started time.Time) {
+			_ = broker.Stop(started)
+		}(s.current, s.started)
+	}
+}
+
+func (s *sweepStopper) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func sweepRuns(ctx *cli.Context) ([]*sweepRun, error) {
+	if path := ctx.String("runs"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read runs file: %w", err)
+		}
+		var runs []*sweepRun
+		if err := json.Unmarshal(data, &runs); err != nil {
+			return nil, fmt.Errorf("unmarshal runs file: %w", err)
+		}
+		for _, run := range runs {
+			if run.Type == "" {
+				run.Type = ctx.String("type")
+			}
+			if run.Nodes == 0 {
+				run.Nodes = 1
+			}
+			if run.Crypto == "" {
+				run.Crypto = ctx.String("crypto")
+			}
+		}
+		return runs, nil
+	}
+
+	concurrents, err := parseIntRange(ctx.String("concurrent"))
+	if err != nil {
+		return nil, err
+	}
+	tpss, err := parseIntRange(ctx.String("tps"))
+	if err != nil {
+		return nil, err
+	}
+	sizes, err := parseIntRange(ctx.String("payload_size"))
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := parseIntRange(ctx.String("nodes"))
+	if err != nil {
+		return nil, err
+	}
+	types := strings.Split(ctx.String("type"), ",")
+	algorithms := strings.Split(ctx.String("crypto"), ",")
+
+	var runs []*sweepRun
+	for _, algorithm := range algorithms {
+		for _, n := range nodes {
+			for _, typ := range types {
+				for _, size := range sizes {
+					for _, c := range concurrents {
+						for _, tps := range tpss {
+							runs = append(runs, &sweepRun{
+								Concurrent:  c,
+								TPS:         tps,
+								Type:        strings.TrimSpace(typ),
+								PayloadSize: size,
+								Nodes:       n,
+								Crypto:      strings.TrimSpace(algorithm),
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+	return runs, nil
+}
+
+// checkSweepRuns rejects runs without load or of an unsupported tx type or crypto algorithm,
+// before the first run funds any account
+func checkSweepRuns(runs []*sweepRun) error {
+	if len(runs) == 0 {
+		return fmt.Errorf("no sweep runs")
+	}
+	for i, run := range runs {
+		if run.Concurrent <= 0 || run.TPS <= 0 {
+			return fmt.Errorf("sweep run %d: concurrent and tps should be positive", i+1)
+		}
+		known := false
+		for _, typ := range sweepTypes {
+			known = known || typ == run.Type
+		}
+		if !known {
+			return fmt.Errorf("sweep run %d: unsupported tx type %s, supported: %s", i+1, run.Type, strings.Join(sweepTypes, ", "))
+		}
+		if _, err := repo.ParseKeyType(run.Crypto); err != nil {
+			return fmt.Errorf("sweep run %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parseIntRange parses a comma separated list of numbers or start:end:step ranges
+func parseIntRange(s string) ([]int, error) {
+	var ret []int
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, ":") {
+			v, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q: %w", item, err)
+			}
+			ret = append(ret, v)
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid range %q, should be start:end:step", item)
+		}
+		var bounds [3]int
+		for i, part := range parts {
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q: %w", item, err)
+			}
+			bounds[i] = v
+		}
+		if bounds[2] <= 0 {
+			return nil, fmt.Errorf("invalid range %q, step should be positive", item)
+		}
+		for v := bounds[0]; v <= bounds[1]; v += bounds[2] {
+			ret = append(ret, v)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSweepRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		runs    []*sweepRun
+		wantErr bool
+	}{
+		{"valid", []*sweepRun{
+			{Concurrent: 10, TPS: 100, Type: "transfer", Crypto: "Secp256k1"},
+			{Concurrent: 10, TPS: 100, Type: "interchain", Crypto: "ECDSA-P256"},
+		}, false},
+		{"default crypto", []*sweepRun{{Concurrent: 1, TPS: 1, Type: "data"}}, false},
+		{"no runs", nil, true},
+		{"zero concurrent", []*sweepRun{{TPS: 100, Type: "transfer"}}, true},
+		{"negative tps", []*sweepRun{{Concurrent: 1, TPS: -1, Type: "transfer"}}, true},
+		{"typo in type", []*sweepRun{{Concurrent: 1, TPS: 1, Type: "tranfser"}}, true},
+		{"governance type", []*sweepRun{{Concurrent: 1, TPS: 1, Type: "governance"}}, true},
+		{"query type", []*sweepRun{{Concurrent: 1, TPS: 1, Type: "query"}}, true},
+		{"unsupported crypto", []*sweepRun{{Concurrent: 1, TPS: 1, Type: "transfer", Crypto: "ED25519"}}, true},
+		{"bad run after good ones", []*sweepRun{
+			{Concurrent: 1, TPS: 1, Type: "transfer"},
+			{Concurrent: 1, TPS: 1, Type: "rollback", Crypto: "rsa"},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSweepRuns(tt.runs)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
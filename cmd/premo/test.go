@@ -7,16 +7,19 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/gobuffalo/packr/v2"
+	"github.com/meshplus/premo/internal/appchain"
 	"github.com/meshplus/premo/internal/bitxhub"
+	"github.com/meshplus/premo/internal/governance"
+	"github.com/meshplus/premo/internal/query"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 	"github.com/urfave/cli/v2"
 )
 
 var testCMD = &cli.Command{
 	Name:  "test",
 	Usage: "test bitxhub function",
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.IntFlag{
 			Name:    "concurrent",
 			Aliases: []string{"c"},
@@ -36,75 +39,226 @@ var testCMD = &cli.Command{
 			Usage:   "test duration",
 		},
 		&cli.StringFlag{
-			Name:    "key_path",
-			Aliases: []string{"k"},
-			Usage:   "Specify key path",
+			Name:  "type",
+			Usage: "Specify tx type: interchain, data, transfer, rollback, governance, query",
+			Value: "transfer",
 		},
-		&cli.StringSliceFlag{
-			Name:    "remote_bitxhub_addr",
-			Aliases: []string{"r"},
-			Usage:   "Specify remote bitxhub address",
-			Value:   cli.NewStringSlice("localhost:60011"),
+		&cli.StringFlag{
+			Name:  "governance_targets",
+			Usage: "Specify the comma separated objects governed by governance type: appchain, service, node, role, dapp, empty for all",
 		},
 		&cli.StringFlag{
-			Name:  "type",
-			Usage: "Specify tx type: interchain, data, transfer",
-			Value: "transfer",
+			Name:  "query_mix",
+			Usage: "Specify the comma separated call=weight mix of query type, calls: block_height, block_hash, blocks, receipt, transaction, balance, chain_meta, appchain, service, empty for all with weight 1",
+		},
+		&cli.IntFlag{
+			Name:  "query_blocks_range",
+			Usage: "Specify the number of blocks fetched by one GetBlocks of query type",
+			Value: query.DefaultBlocksRange,
+		},
+		&cli.IntFlag{
+			Name:  "payload_size",
+			Usage: "Specify the size in bytes of the value stored by data tx",
 		},
 		&cli.StringFlag{
-			Name:  "appchain",
-			Usage: "Specify appchain type: fabric, flato, eth",
-			Value: "flato",
-		},
-		&cli.BoolFlag{
-			Name:    "graph",
-			Usage:   "Graph tps and latency",
-			Aliases: []string{"g"},
-			Value:   false,
-		},
-		&cli.BoolFlag{
-			Name:    "multiDestChain",
-			Usage:   "Specify different src annchain send tx to different dest appchain",
-			Aliases: []string{"m"},
-			Value:   false,
+			Name:  "crypto",
+			Usage: "Specify algorithm of bee accounts: Secp256k1, SM2, ECDSA-P256",
+			Value: "Secp256k1",
 		},
 		&cli.IntFlag{
-			Name:  "timeoutHeight",
-			Value: 0,
-			Usage: "interchain timeoutHeight",
+			Name:  "soak_interval",
+			Usage: "seconds between rolling soak reports flagging leaks and degradation, 0 disables them",
 		},
-	},
+		&cli.IntFlag{
+			Name:  "total",
+			Usage: "send exactly total txs and fail unless all are committed within the duration, 0 sends for the duration",
+		},
+		&cli.IntFlag{
+			Name:  "status_sample",
+			Usage: "track the final status of one ibtp in every status_sample sent by interchain types, 1 tracks all, 0 disables it",
+		},
+		&cli.StringFlag{
+			Name:  "soak_output",
+			Usage: "Specify file the rolling soak reports are appended to",
+			Value: bitxhub.DefaultSoakOutput,
+		},
+		&cli.StringFlag{
+			Name:  "scenario",
+			Usage: "Specify scenario file, flags set on the command line take precedence",
+		},
+		&cli.Float64Flag{
+			Name:  "min_tps",
+			Usage: "Assert the minimum tps of the benchmark",
+		},
+		&cli.Int64Flag{
+			Name:  "max_p99_latency",
+			Usage: "Assert the maximum p99 tx latency in milliseconds",
+		},
+		&cli.Float64Flag{
+			Name:  "max_failure_ratio",
+			Usage: "Assert the maximum ratio of failed tx sends",
+		},
+		&cli.Int64Flag{
+			Name:  "max_missing",
+			Usage: "Assert the maximum number of txs sent by bees and missing from blocks, other txs of the chain do not count",
+		},
+	}, append(sendFlags, collectFlags...)...),
 	Action: benchmark,
 }
 
+// sendFlags are the flags of how bees send txs shared by test and sweep, every run of a
+// sweep takes them as they are
+var sendFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "key_path",
+		Aliases: []string{"k"},
+		Usage:   "Specify key path",
+	},
+	&cli.StringSliceFlag{
+		Name:    "remote_bitxhub_addr",
+		Aliases: []string{"r"},
+		Usage:   "Specify remote bitxhub address",
+		Value:   cli.NewStringSlice("localhost:60011"),
+	},
+	&cli.StringFlag{
+		Name:  "arrival",
+		Usage: "Specify arrival process of txs: uniform, poisson",
+		Value: bitxhub.ArrivalUniform,
+	},
+	&cli.IntFlag{
+		Name:  "batch_size",
+		Usage: "Specify the number of txs sent in one batch",
+		Value: bitxhub.DefaultBatchSize,
+	},
+	&cli.IntFlag{
+		Name:  "retry_max_attempts",
+		Usage: "Specify the max attempts to send a batch of txs, 0 retries forever",
+		Value: bitxhub.DefaultRetryPolicy().MaxAttempts,
+	},
+	&cli.IntFlag{
+		Name:  "retry_backoff",
+		Usage: "Specify the backoff before the first retry in milliseconds, doubled every retry",
+		Value: int(bitxhub.DefaultRetryPolicy().Backoff / time.Millisecond),
+	},
+	&cli.IntFlag{
+		Name:  "retry_max_backoff",
+		Usage: "Specify the max backoff between retries in milliseconds",
+		Value: int(bitxhub.DefaultRetryPolicy().MaxBackoff / time.Millisecond),
+	},
+	&cli.StringFlag{
+		Name:  "retry_exhausted",
+		Usage: "Specify what to do with a batch whose retries are exhausted: drop, resync",
+		Value: bitxhub.DefaultRetryPolicy().OnExhausted,
+	},
+	&cli.StringFlag{
+		Name:  "bxh_id",
+		Usage: "Specify chain ID of the relay chain in full service IDs",
+		Value: service.DefaultBxhID,
+	},
+	&cli.StringFlag{
+		Name:  "dest_bxh_id",
+		Usage: "Specify chain ID of the relay chain of dest appchains, defaults to bxh_id",
+	},
+	&cli.StringFlag{
+		Name:  "appchain",
+		Usage: "Specify appchain profile: fabric, flato, eth, hyperchain, bcos or one in appchains.json",
+		Value: "flato",
+	},
+	&cli.StringFlag{
+		Name:  "validator",
+		Usage: "Specify trust root file of the appchain instead of the one of the profile",
+	},
+	&cli.StringFlag{
+		Name:  "proof",
+		Usage: "Specify proof file, or directory of proof files cycled through, instead of the one of the profile",
+	},
+	&cli.BoolFlag{
+		Name:    "graph",
+		Usage:   "Graph tps and latency",
+		Aliases: []string{"g"},
+		Value:   false,
+	},
+	&cli.BoolFlag{
+		Name:    "multiDestChain",
+		Usage:   "Specify different src annchain send tx to different dest appchain",
+		Aliases: []string{"m"},
+		Value:   false,
+	},
+	&cli.BoolFlag{
+		Name:  "ignore_funds",
+		Usage: "Warn instead of refusing to run when the admin or voters cannot afford the setup",
+	},
+	&cli.BoolFlag{
+		Name:  "collect_funds",
+		Usage: "Send what is left on the bee accounts back to the admin after the run",
+	},
+	&cli.IntFlag{
+		Name:  "timeoutHeight",
+		Value: 0,
+		Usage: "interchain timeoutHeight, defaults to 5 for rollback",
+	},
+	&cli.IntFlag{
+		Name:  "clock_warmup",
+		Usage: "seconds to estimate the clock offset to bitxhub nodes, latencies are not measured meanwhile, 0 disables it",
+	},
+}
+
 func benchmark(ctx *cli.Context) error {
-	box := packr.New(repo.ConfigPath, repo.ConfigPath)
-	//val, err := box.Find("validator_fabric")
-	val, err := box.Find("single_validator")
+	scenario, err := loadScenario(ctx.String("scenario"))
+	if err != nil {
+		return err
+	}
+	switch scenario.stringValue(ctx, "type", scenario.Type) {
+	case governance.Type:
+		return governanceBenchmark(ctx, scenario)
+	case query.Type:
+		return queryBenchmark(ctx, scenario)
+	}
+	config, err := newBenchmarkConfig(ctx, scenario)
 	if err != nil {
 		return err
 	}
-	var proof []byte
-	typ := ""
-	proof = []byte("111")
+	assertions := scenario.assertions(ctx)
+	interrupted := 0
+	if assertions.Enabled() || config.Total > 0 {
+		// an exact-count run interrupted before total is done did not commit every tx
+		interrupted = bitxhub.ExitNoReport
+	}
+	report, err := runBenchmark(config, interrupted)
+	if err != nil {
+		return err
+	}
+	if report == nil && config.Total > 0 {
+		return cli.Exit(fmt.Sprintf("exact-count run of %d txs interrupted before its report", config.Total), bitxhub.ExitNoReport)
+	}
 
-	appchain := ctx.String("appchain")
-	if appchain == "fabric" {
-		val, err = box.Find("validator_fabric_complex")
-		if err != nil {
-			return err
-		}
-		proof, err = box.Find("proof_fabric")
-		if err != nil {
-			return err
-		}
-		typ = "Fabric V1.4.3"
-	} else if appchain == "flato" {
-		typ = "Flato V1.0.3"
-	} else if appchain == "eth" {
-		typ = "ETH"
+	return checkAssertions(assertions, report)
+}
+
+func newBenchmarkConfig(ctx *cli.Context, scenario *Scenario) (*bitxhub.Config, error) {
+	profile, err := appchain.Lookup(scenario.stringValue(ctx, "appchain", scenario.Appchain))
+	if err != nil {
+		return nil, err
+	}
+	var val []byte
+	if path := scenario.stringValue(ctx, "validator", scenario.Validator); path != "" {
+		val, err = profile.LoadTrustRoot(path)
+	} else {
+		val, err = profile.TrustRootData()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read trust root of %s: %w", profile.Name, err)
+	}
+	var proofs [][]byte
+	if path := scenario.stringValue(ctx, "proof", scenario.Proof); path != "" {
+		proofs, err = profile.LoadProofs(path)
 	} else {
-		return fmt.Errorf("unsupported appchain type")
+		var proof []byte
+		proof, err = profile.Proof()
+		proofs = [][]byte{proof}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate proof of %s: %w", profile.Name, err)
 	}
 
 	keyPath := ctx.String("key_path")
@@ -112,54 +266,134 @@ func benchmark(ctx *cli.Context) error {
 		//default use node4
 		keyPath, err = repo.Node4Path()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	config := &bitxhub.Config{
-		Concurrent:     ctx.Int("concurrent"),
-		TPS:            ctx.Int("tps"),
-		Duration:       ctx.Int("duration"),
-		Type:           ctx.String("type"),
+	return &bitxhub.Config{
+		Concurrent:  scenario.intValue(ctx, "concurrent", scenario.Concurrent),
+		TPS:         scenario.intValue(ctx, "tps", scenario.TPS),
+		Duration:    scenario.intValue(ctx, "duration", scenario.Duration),
+		Type:        scenario.stringValue(ctx, "type", scenario.Type),
+		PayloadSize: scenario.intValue(ctx, "payload_size", scenario.PayloadSize),
+		Arrival:     scenario.stringValue(ctx, "arrival", scenario.Arrival),
+		BatchSize:   scenario.intValue(ctx, "batch_size", scenario.BatchSize),
+		Retry: bitxhub.RetryPolicy{
+			MaxAttempts: ctx.Int("retry_max_attempts"),
+			Backoff:     time.Duration(ctx.Int("retry_backoff")) * time.Millisecond,
+			MaxBackoff:  time.Duration(ctx.Int("retry_max_backoff")) * time.Millisecond,
+			OnExhausted: ctx.String("retry_exhausted"),
+		},
 		KeyPath:        keyPath,
 		BitxhubAddr:    ctx.StringSlice("remote_bitxhub_addr"),
 		Validator:      string(val),
-		Proof:          proof,
-		Appchain:       typ,
+		Proofs:         proofs,
+		Appchain:       profile,
 		Graph:          ctx.Bool("graph"),
 		MultiDestChain: ctx.Bool("multiDestChain"),
 		TimeoutHeight:  ctx.Int("timeoutHeight"),
-	}
-
-	if config.Concurrent > config.TPS {
-		return fmt.Errorf("error: concurrent should be less than tps")
-	}
+		ClockWarmup:    ctx.Int("clock_warmup"),
+		BxhID:          ctx.String("bxh_id"),
+		Crypto:         ctx.String("crypto"),
+		DestBxhID:      ctx.String("dest_bxh_id"),
+		SoakInterval:   scenario.intValue(ctx, "soak_interval", scenario.SoakInterval),
+		SoakOutput:     scenario.stringValue(ctx, "soak_output", scenario.SoakOutput),
+		StatusSample:   scenario.intValue(ctx, "status_sample", scenario.StatusSample),
+		Total:          scenario.intValue(ctx, "total", scenario.Total),
+		IgnoreFunds:    ctx.Bool("ignore_funds"),
+		CollectFunds:   ctx.Bool("collect_funds"),
+		Collect:        newCollectConfig(ctx),
+	}, nil
+}
 
-	broker, err := bitxhub.New(config)
+// runBenchmark runs a benchmark and returns its report, the report is nil if the benchmark was interrupted.
+// An interrupt signal exits the process with the code interrupted.
+func runBenchmark(config *bitxhub.Config, interrupted int) (*bitxhub.Report, error) {
+	broker, err := newBroker(config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	handleShutdown(broker)
+	release := handleShutdown(broker, interrupted)
+	defer release()
 
 	err = broker.Start()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return broker.Report(), nil
+}
+
+// newBroker checks config and prepares the broker of a benchmark
+func newBroker(config *bitxhub.Config) (*bitxhub.Broker, error) {
+	if config.Concurrent > config.TPS {
+		return nil, fmt.Errorf("error: concurrent should be less than tps")
 	}
+	return bitxhub.New(config)
+}
 
+// checkAssertions prints every failed assertion and returns an exit error
+// whose code or-s the codes of all failed assertions, a missing report fails enabled assertions
+func checkAssertions(assertions *bitxhub.Assertions, report *bitxhub.Report) error {
+	if report == nil {
+		if assertions.Enabled() {
+			return cli.Exit("benchmark produced no report, assertions not evaluated", bitxhub.ExitNoReport)
+		}
+		return nil
+	}
+	code := 0
+	for _, failure := range assertions.Evaluate(report) {
+		fmt.Println(failure.String())
+		code |= failure.ExitCode
+	}
+	if code != 0 {
+		return cli.Exit("benchmark assertions failed", code)
+	}
 	return nil
 }
 
-func handleShutdown(node *bitxhub.Broker) {
+// handleShutdown stops the broker on interrupt signal and exits with code, until the returned function is called
+func handleShutdown(node *bitxhub.Broker, code int) func() {
 	current := time.Now()
 	var stop = make(chan os.Signal, 1)
+	var done = make(chan struct{})
 	signal.Notify(stop, syscall.SIGTERM)
 	signal.Notify(stop, syscall.SIGINT)
 	go func() {
-		<-stop
+		select {
+		case <-done:
+			return
+		case <-stop:
+		}
 		fmt.Println("received interrupt signal, shutting down...")
 		if err := node.Stop(current); err != nil {
 			panic(err)
 		}
-		os.Exit(0)
+		os.Exit(code)
 	}()
+	return func() {
+		signal.Stop(stop)
+		close(done)
+	}
+}
+
+// handleStop calls stop on interrupt signal until the returned function is called
+func handleStop(stop func()) func() {
+	var signals = make(chan os.Signal, 1)
+	var done = make(chan struct{})
+	signal.Notify(signals, syscall.SIGTERM)
+	signal.Notify(signals, syscall.SIGINT)
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-signals:
+		}
+		fmt.Println("received interrupt signal, shutting down...")
+		stop()
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
 }
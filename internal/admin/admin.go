@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-kit/fileutil"
+	"github.com/meshplus/bitxhub-kit/types"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
+)
+
+// defaultKeys are the admins of the default 4-node genesis
+var defaultKeys = []string{"node1.json", "node2.json", "node3.json", "node4.json"}
+
+// KeyConfig is one entry of admins.json
+type KeyConfig struct {
+	Key    string `json:"key"`    // key file, relative to the repo root
+	Weight uint64 `json:"weight"` // the weight of the admin role, 1 if omitted
+}
+
+// Admin is a governance admin able to vote
+type Admin struct {
+	Path    string
+	Weight  uint64
+	Key     crypto.PrivateKey
+	Address *types.Address
+
+	lock   sync.Mutex
+	synced bool
+	nonce  uint64
+}
+
+// NextNonce returns the nonce of the next tx sent by the admin, the first call
+// syncs the pending nonce from the node
+func (a *Admin) NextNonce(client rpcx.Client) (uint64, error) {
+	a.lock.Lock()
+	if !a.synced {
+		nonce, err := client.GetPendingNonceByAccount(a.Address.String())
+		if err != nil {
+			a.lock.Unlock()
+			return 0, err
+		}
+		a.nonce = nonce
+		a.synced = true
+	}
+	a.lock.Unlock()
+	return atomic.AddUint64(&a.nonce, 1) - 1, nil
+}
+
+// Set is the admin set used to vote proposals
+type Set struct {
+	Admins []*Admin
+}
+
+// Load loads the admin set from admins.json in the repo, it falls back to the
+// node1 to node4 keys with weight 1 if the file does not exist
+func Load() (*Set, error) {
+	path, err := repo.AdminsPath()
+	if err != nil {
+		return nil, err
+	}
+	if !fileutil.Exist(path) {
+		keys := make([]*KeyConfig, 0, len(defaultKeys))
+		for _, key := range defaultKeys {
+			keys = append(keys, &KeyConfig{Key: key, Weight: 1})
+		}
+		return NewSet(keys)
+	}
+	return LoadFile(path)
+}
+
+// LoadFile loads the admin set from a JSON list of KeyConfig
+func LoadFile(path string) (*Set, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []*KeyConfig
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("unmarshal admins %s: %w", path, err)
+	}
+	return NewSet(keys)
+}
+
+// NewSet decrypts the key files of the admins
+func NewSet(keys []*KeyConfig) (*Set, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("admin set is empty")
+	}
+	set := &Set{}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		pk, addr, err := repo.PrivByPath(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("load admin key %s: %w", key.Key, err)
+		}
+		if seen[addr.String()] {
+			return nil, fmt.Errorf("admin %s is duplicated", addr.String())
+		}
+		seen[addr.String()] = true
+		weight := key.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		set.Admins = append(set.Admins, &Admin{
+			Path:    key.Key,
+			Weight:  weight,
+			Key:     pk,
+			Address: addr,
+		})
+	}
+	return set, nil
+}
+
+// byWeight returns the admins with the heaviest first, so the fewest votes reach a quorum
+func (s *Set) byWeight() []*Admin {
+	admins := make([]*Admin, len(s.Admins))
+	copy(admins, s.Admins)
+	sort.SliceStable(admins, func(i, j int) bool { return admins[i].Weight > admins[j].Weight })
+	return admins
+}
+
+// TotalWeight returns the sum of the weights of all admins
+func (s *Set) TotalWeight() uint64 {
+	var total uint64
+	for _, admin := range s.Admins {
+		total += admin.Weight
+	}
+	return total
+}
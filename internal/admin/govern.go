@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bxhgov "github.com/meshplus/bitxhub-core/governance"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+)
+
+// StatusPollInterval is the interval WaitStatus queries the governed object
+const StatusPollInterval = 100 * time.Millisecond
+
+// RegisterResult is the result of a governance method creating a proposal
+type RegisterResult struct {
+	Extra      []byte `json:"extra"`
+	ProposalID string `json:"proposal_id"`
+}
+
+// ProposalID returns the proposal created by the governance method of the receipt, it is
+// empty if the method needed none
+func ProposalID(receipt *pb.Receipt) (string, error) {
+	result := &RegisterResult{}
+	if len(receipt.Ret) != 0 {
+		if err := json.Unmarshal(receipt.Ret, result); err != nil {
+			return "", fmt.Errorf("unmarshal proposal: %w", err)
+		}
+	}
+	return result.ProposalID, nil
+}
+
+// Governor returns the admin sending the governance txs reserved to admins
+func (s *Set) Governor() *Admin {
+	return s.Admins[0]
+}
+
+// GovernorOpts returns the options of a tx sent by the governor
+func (s *Set) GovernorOpts(client rpcx.Client) (*rpcx.TransactOpts, error) {
+	governor := s.Governor()
+	nonce, err := governor.NextNonce(client)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcx.TransactOpts{
+		From:    governor.Address.String(),
+		Nonce:   nonce,
+		PrivKey: governor.Key,
+	}, nil
+}
+
+// Govern calls method of contract as the governor and approves the proposal it creates
+func (v *Voter) Govern(contract *types.Address, method string, args ...*pb.Arg) error {
+	opts, err := v.Admins.GovernorOpts(v.Client)
+	if err != nil {
+		return err
+	}
+	receipt, err := v.Client.InvokeBVMContract(contract, method, opts, args...)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != pb.Receipt_SUCCESS {
+		return fmt.Errorf("%s: %s", method, string(receipt.Ret))
+	}
+	id, err := ProposalID(receipt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if id == "" {
+		return nil
+	}
+	return v.Vote(id, Approve)
+}
+
+// Object is the part of a governed object needed to follow its status
+type Object struct {
+	Status bxhgov.GovernanceStatus `json:"status"`
+}
+
+// GetObject queries a governed object with the view method of contract without sending a tx
+func GetObject(client rpcx.Client, contract *types.Address, method string, args ...*pb.Arg) (*Object, error) {
+	tx, err := client.GenerateContractTx(pb.TransactionData_BVM, contract, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.SendView(tx)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != pb.Receipt_SUCCESS {
+		return nil, fmt.Errorf("%s: %s", method, string(res.Ret))
+	}
+	obj := &Object{}
+	if err := json.Unmarshal(res.Ret, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// WaitStatus polls the governed object until it is in status want or ctx is done
+func WaitStatus(ctx context.Context, client rpcx.Client, want bxhgov.GovernanceStatus, contract *types.Address, method string, args ...*pb.Arg) error {
+	ticker := time.NewTicker(StatusPollInterval)
+	defer ticker.Stop()
+	for {
+		obj, err := GetObject(client, contract, method, args...)
+		if err != nil {
+			return err
+		}
+		if obj.Status == want {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("still %s, want %s", obj.Status, want)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+)
+
+// status of a proposal
+const (
+	ProposalProposed = "proposed"
+	ProposalApproved = "approve"
+	ProposalRejected = "reject"
+	ProposalPaused   = "pause"
+)
+
+// info of a vote
+const (
+	Approve = "approve"
+	Reject  = "reject"
+)
+
+// ZeroPermission is the strategy that approves proposals without votes
+const ZeroPermission = "ZeroPermission"
+
+// Proposal is the part of a governance proposal needed to vote it
+type Proposal struct {
+	Id                 string                 `json:"id"`
+	Typ                string                 `json:"typ"`
+	Status             string                 `json:"status"`
+	BallotMap          map[string]interface{} `json:"ballot_map"`
+	ApproveNum         uint64                 `json:"approve_num"`
+	AgainstNum         uint64                 `json:"against_num"`
+	ElectorateNum      uint64                 `json:"electorate_num"`
+	StrategyExpression string                 `json:"strategy_expression"`
+}
+
+// Strategy is the proposal strategy of a governance module
+type Strategy struct {
+	Module string `json:"module"`
+	Typ    string `json:"typ"`
+	Extra  string `json:"extra"`
+	Status string `json:"status"`
+}
+
+// GetProposal queries the proposal from the governance contract
+func GetProposal(client rpcx.Client, id string) (*Proposal, error) {
+	res, err := view(client, constant.GovernanceContractAddr.Address(), "GetProposal", rpcx.String(id))
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != pb.Receipt_SUCCESS {
+		return nil, fmt.Errorf("get proposal %s: %s", id, string(res.Ret))
+	}
+	proposal := &Proposal{}
+	if err := json.Unmarshal(res.Ret, proposal); err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// GetStrategy queries the proposal strategy of module, e.g. appchain_mgr
+func GetStrategy(client rpcx.Client, module string) (*Strategy, error) {
+	res, err := view(client, constant.ProposalStrategyMgrContractAddr.Address(), "GetProposalStrategy", rpcx.String(module))
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != pb.Receipt_SUCCESS {
+		return nil, fmt.Errorf("get strategy of %s: %s", module, string(res.Ret))
+	}
+	strategy := &Strategy{}
+	if err := json.Unmarshal(res.Ret, strategy); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+// view calls a BVM method without sending a tx, so it takes no nonce of the client account
+func view(client rpcx.Client, address *types.Address, method string, args ...*pb.Arg) (*pb.Receipt, error) {
+	tx, err := client.GenerateContractTx(pb.TransactionData_BVM, address, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return client.SendView(tx)
+}
+
+// thresholdExpr matches the expressions of the simple majority family, e.g. "a > 0.5 * t"
+var thresholdExpr = regexp.MustCompile(`^\s*a\s*(>=|>)\s*([0-9.]+)\s*\*\s*t\s*$`)
+
+// Quorum returns the approving weight a proposal needs out of total under the expression,
+// ok is false if the expression is not of the form "a > k * t" or "a >= k * t"
+func Quorum(expression string, total uint64) (uint64, bool) {
+	m := thresholdExpr.FindStringSubmatch(expression)
+	if m == nil {
+		return 0, false
+	}
+	k, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return 0, false
+	}
+	bound := k * float64(total)
+	if m[1] == ">=" {
+		return uint64(math.Ceil(bound)), true
+	}
+	return uint64(math.Floor(bound)) + 1, true
+}
+
+// Quorum returns the approving weight needed out of total under the strategy
+func (s *Strategy) Quorum(total uint64) (uint64, bool) {
+	if s.Typ == ZeroPermission {
+		return 0, true
+	}
+	return Quorum(s.Extra, total)
+}
+
+// Voter votes proposals with an admin set until they leave the proposed status
+type Voter struct {
+	Client rpcx.Client
+	Admins *Set
+	Reason string
+	// Nonce returns the nonce for the vote of admin, defaults to Admin.NextNonce,
+	// callers sharing admin keys with other txs supply their own counters
+	Nonce func(admin *Admin) (uint64, error)
+}
+
+// NewVoter returns a voter
+func NewVoter(client rpcx.Client, admins *Set, reason string) *Voter {
+	return &Voter{
+		Client: client,
+		Admins: admins,
+		Reason: reason,
+	}
+}
+
+// Vote casts info votes on proposal id, one admin after another, until the proposal is
+// approved or rejected. A proposal already settled as wanted is not voted again.
+func (v *Voter) Vote(id, info string) error {
+	proposal, err := GetProposal(v.Client, id)
+	if err != nil {
+		return err
+	}
+	total := proposal.ElectorateNum
+	if total == 0 {
+		total = v.Admins.TotalWeight()
+	}
+	if info == Approve {
+		if quorum, ok := Quorum(proposal.StrategyExpression, total); ok && quorum > v.Admins.TotalWeight() {
+			return fmt.Errorf("proposal %s needs approving weight %d but admins only have %d",
+				id, quorum, v.Admins.TotalWeight())
+		}
+	}
+
+	for _, admin := range v.Admins.byWeight() {
+		if settled, err := v.settled(proposal, info); settled || err != nil {
+			return err
+		}
+		if _, ok := proposal.BallotMap[admin.Address.String()]; ok {
+			continue
+		}
+		voteErr := v.vote(admin, id, info)
+		proposal, err = GetProposal(v.Client, id)
+		if err != nil {
+			return err
+		}
+		// the proposal may be settled by others between the query and the vote
+		if voteErr != nil {
+			if settled, err := v.settled(proposal, info); settled {
+				return err
+			}
+			return voteErr
+		}
+	}
+	if settled, err := v.settled(proposal, info); settled || err != nil {
+		return err
+	}
+	return fmt.Errorf("proposal %s is still %s after all admins voted", id, proposal.Status)
+}
+
+// settled reports whether the proposal left the proposed status, it is an error if
+// it settled against info
+func (v *Voter) settled(proposal *Proposal, info string) (bool, error) {
+	switch proposal.Status {
+	case ProposalProposed, ProposalPaused:
+		return false, nil
+	case ProposalApproved:
+		if info != Approve {
+			return true, fmt.Errorf("proposal %s is already approved", proposal.Id)
+		}
+		return true, nil
+	case ProposalRejected:
+		if info != Reject {
+			return true, fmt.Errorf("proposal %s is already rejected", proposal.Id)
+		}
+		return true, nil
+	default:
+		return true, fmt.Errorf("proposal %s is %s", proposal.Id, proposal.Status)
+	}
+}
+
+func (v *Voter) vote(admin *Admin, id, info string) error {
+	var (
+		nonce uint64
+		err   error
+	)
+	if v.Nonce != nil {
+		nonce, err = v.Nonce(admin)
+	} else {
+		nonce, err = admin.NextNonce(v.Client)
+	}
+	if err != nil {
+		return err
+	}
+	res, err := v.Client.InvokeBVMContract(constant.GovernanceContractAddr.Address(), "Vote", &rpcx.TransactOpts{
+		From:    admin.Address.String(),
+		Nonce:   nonce,
+		PrivKey: admin.Key,
+	}, rpcx.String(id), rpcx.String(info), rpcx.String(v.Reason))
+	if err != nil {
+		return err
+	}
+	if res.Status != pb.Receipt_SUCCESS {
+		return fmt.Errorf("vote err: %s", string(res.Ret))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorum(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		total      uint64
+		want       uint64
+		ok         bool
+	}{
+		{"simple majority of 4", "a > 0.5 * t", 4, 3, true},
+		{"simple majority of 3", "a > 0.5 * t", 3, 2, true},
+		{"at least half of 4", "a >= 0.5 * t", 4, 2, true},
+		{"at least half of 3", "a >= 0.5 * t", 3, 2, true},
+		{"two thirds of 4", "a >= 0.67 * t", 4, 3, true},
+		{"all of 4", "a >= 1 * t", 4, 4, true},
+		{"no spaces", "a>0.5*t", 4, 3, true},
+		{"of no weight", "a > 0.5 * t", 0, 1, true},
+		{"other variable", "r > 0.5 * t", 4, 0, false},
+		{"other form", "a > 0.5 * t && r == 0", 4, 0, false},
+		{"empty", "", 4, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Quorum(tt.expression, tt.total)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStrategyQuorum(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy *Strategy
+		want     uint64
+		ok       bool
+	}{
+		{"zero permission", &Strategy{Typ: ZeroPermission}, 0, true},
+		{"simple majority", &Strategy{Typ: "SimpleMajority", Extra: "a > 0.5 * t"}, 3, true},
+		{"unknown expression", &Strategy{Typ: "SimpleMajority", Extra: "a == t"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.strategy.Quorum(4)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
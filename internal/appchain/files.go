@@ -0,0 +1,107 @@
+package appchain
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meshplus/bitxhub-core/validator/validatorlib"
+)
+
+// IsFabric reports whether the appchain is validated by the fabric validation engine
+func (p *Profile) IsFabric() bool {
+	return strings.HasPrefix(strings.ToLower(p.ChainType), "fabric")
+}
+
+// LoadTrustRoot reads a custom trust root file and checks it fits the appchain type
+func (p *Profile) LoadTrustRoot(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read trust root: %w", err)
+	}
+	if err := p.CheckTrustRoot(data); err != nil {
+		return nil, fmt.Errorf("trust root %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// LoadProofs reads a custom proof file, or every regular file of a proof directory in
+// name order, and checks they fit the appchain type
+func (p *Profile) LoadProofs(path string) ([][]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read proof: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("read proof directory: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.Mode().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+		sort.Strings(files)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("proof directory %s has no proof file", path)
+		}
+	}
+
+	proofs := make([][]byte, 0, len(files))
+	for _, file := range files {
+		proof, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read proof: %w", err)
+		}
+		if err := p.CheckProof(proof); err != nil {
+			return nil, fmt.Errorf("proof %s: %w", file, err)
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs, nil
+}
+
+// CheckTrustRoot checks the trust root can be parsed by the validation engine of the appchain type
+func (p *Profile) CheckTrustRoot(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("trust root is empty")
+	}
+	if !p.IsFabric() {
+		return nil
+	}
+	info, err := validatorlib.UnmarshalValidatorInfo(data)
+	if err != nil {
+		return fmt.Errorf("not a fabric validator of %s: %w", p.ChainType, err)
+	}
+	if len(info.ConfByte) == 0 {
+		return fmt.Errorf("fabric validator has no conf_byte")
+	}
+	if info.Policy == "" {
+		return fmt.Errorf("fabric validator has no policy")
+	}
+	if info.Cid == "" {
+		return fmt.Errorf("fabric validator has no cid")
+	}
+	return nil
+}
+
+// CheckProof checks the proof can be parsed by the validation engine of the appchain type
+func (p *Profile) CheckProof(proof []byte) error {
+	if len(proof) == 0 {
+		return fmt.Errorf("proof is empty")
+	}
+	if !p.IsFabric() {
+		return nil
+	}
+	if _, err := validatorlib.ExtractValidationArtifacts(proof); err != nil {
+		return fmt.Errorf("not a fabric proof of %s: %w", p.ChainType, err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package appchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meshplus/bitxhub-kit/fileutil"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
+)
+
+const (
+	// ProfilesFile is the file in the repo root adding or overriding profiles
+	ProfilesFile = "appchains.json"
+
+	// ProofMock generates the fixed proof accepted by the builtin always-pass rules
+	ProofMock = "mock"
+	// ProofFile reads the proof from Profile.ProofFile
+	ProofFile = "file"
+
+	mockProof = "111"
+)
+
+const (
+	defaultBroker = "0x857133c5C69e6Ce66F7AD46F200B9B3573e77582"
+	fabricBroker  = "{\"channel_id\":\"mychannel\",\"chaincode_id\":\"broker\",\"broker_version\":\"1\"}"
+	// HappyRuleAddr is the builtin rule passing every proof
+	HappyRuleAddr  = "0x00000000000000000000000000000000000000a2"
+	fabricRuleAddr = "0x00000000000000000000000000000000000000a0"
+)
+
+// Profile is everything needed to register an appchain of one type and to prove its ibtps.
+// Files are relative to the repo root.
+type Profile struct {
+	Name           string `json:"name"`
+	ChainType      string `json:"chain_type"`
+	Broker         string `json:"broker"`
+	RuleAddr       string `json:"rule_addr"` // builtin rule, used when RuleWasm is empty
+	RuleWasm       string `json:"rule_wasm"` // rule contract deployed for every appchain
+	TrustRoot      string `json:"trust_root"`
+	ProofGenerator string `json:"proof_generator"` // mock or file
+	ProofFile      string `json:"proof_file"`
+}
+
+// builtins are the profiles available without appchains.json
+var builtins = []*Profile{
+	{
+		Name:           "fabric",
+		ChainType:      "Fabric V1.4.3",
+		Broker:         fabricBroker,
+		RuleAddr:       fabricRuleAddr,
+		TrustRoot:      "validator_fabric_complex",
+		ProofGenerator: ProofFile,
+		ProofFile:      "proof_fabric",
+	},
+	{
+		Name:           "flato",
+		ChainType:      "Flato V1.0.3",
+		Broker:         defaultBroker,
+		RuleWasm:       "rule.wasm",
+		TrustRoot:      "single_validator",
+		ProofGenerator: ProofMock,
+	},
+	{
+		Name:           "eth",
+		ChainType:      "ETH",
+		Broker:         defaultBroker,
+		RuleAddr:       HappyRuleAddr,
+		TrustRoot:      "single_validator",
+		ProofGenerator: ProofMock,
+	},
+	{
+		Name:           "hyperchain",
+		ChainType:      "Hyperchain V1.8.3",
+		Broker:         defaultBroker,
+		RuleAddr:       HappyRuleAddr,
+		TrustRoot:      "single_validator",
+		ProofGenerator: ProofMock,
+	},
+	{
+		Name:           "bcos",
+		ChainType:      "BCOS V2.6.0",
+		Broker:         defaultBroker,
+		RuleAddr:       HappyRuleAddr,
+		TrustRoot:      "single_validator",
+		ProofGenerator: ProofMock,
+	},
+	{
+		// a fabric chain whose ibtps always pass, the test server relays mock ibtps with it
+		Name:           "fabric_mock",
+		ChainType:      "Fabric V1.4.3",
+		Broker:         fabricBroker,
+		RuleAddr:       HappyRuleAddr,
+		ProofGenerator: ProofMock,
+	},
+}
+
+// Registry holds the appchain profiles by name
+type Registry struct {
+	profiles map[string]*Profile
+}
+
+// Load returns the builtin profiles overlaid with the ones in appchains.json of the repo
+func Load() (*Registry, error) {
+	r := &Registry{profiles: make(map[string]*Profile)}
+	for _, p := range builtins {
+		profile := *p
+		r.profiles[p.Name] = &profile
+	}
+
+	repoRoot, err := repo.PathRoot()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(repoRoot, ProfilesFile)
+	if !fileutil.Exist(path) {
+		return r, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	for _, p := range profiles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("appchain profile %s in %s: %w", p.Name, path, err)
+		}
+		r.profiles[p.Name] = p
+	}
+	return r, nil
+}
+
+// Get returns the profile called name
+func (r *Registry) Get(name string) (*Profile, error) {
+	p, ok := r.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported appchain type %s, registered: %s", name, strings.Join(r.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Names returns the names of all profiles in order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.profiles))
+	for name := range r.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup loads the registry and returns the profile called name
+func Lookup(name string) (*Profile, error) {
+	r, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(name)
+}
+
+func (p *Profile) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if p.ChainType == "" {
+		return fmt.Errorf("chain type is empty")
+	}
+	if p.Broker == "" {
+		return fmt.Errorf("broker is empty")
+	}
+	if p.RuleAddr == "" && p.RuleWasm == "" {
+		return fmt.Errorf("neither rule address nor rule wasm is set")
+	}
+	switch p.ProofGenerator {
+	case "", ProofMock:
+	case ProofFile:
+		if p.ProofFile == "" {
+			return fmt.Errorf("proof generator is file but proof file is empty")
+		}
+	default:
+		return fmt.Errorf("unsupported proof generator %s", p.ProofGenerator)
+	}
+	return nil
+}
+
+// TrustRootData reads the trust root, which is empty if the profile has none
+func (p *Profile) TrustRootData() ([]byte, error) {
+	if p.TrustRoot == "" {
+		return nil, nil
+	}
+	return readFile(p.TrustRoot)
+}
+
+// Proof generates the proof carried by the ibtps of the appchain
+func (p *Profile) Proof() ([]byte, error) {
+	switch p.ProofGenerator {
+	case ProofFile:
+		return readFile(p.ProofFile)
+	default:
+		return []byte(mockProof), nil
+	}
+}
+
+// Rule returns the master rule address, a wasm rule is deployed with opts first
+// and deployed reports whether a tx was sent
+func (p *Profile) Rule(client rpcx.Client, opts *rpcx.TransactOpts) (addr string, deployed bool, err error) {
+	if p.RuleWasm == "" {
+		return p.RuleAddr, false, nil
+	}
+	contract, err := readFile(p.RuleWasm)
+	if err != nil {
+		return "", false, fmt.Errorf("read rule file err %w", err)
+	}
+	address, err := client.DeployContract(contract, opts)
+	if err != nil {
+		return "", false, fmt.Errorf("deploy rule err %w", err)
+	}
+	return address.String(), true, nil
+}
+
+func readFile(name string) ([]byte, error) {
+	if !filepath.IsAbs(name) {
+		repoRoot, err := repo.PathRoot()
+		if err != nil {
+			return nil, err
+		}
+		name = filepath.Join(repoRoot, name)
+	}
+	return ioutil.ReadFile(name)
+}
@@ -0,0 +1,36 @@
+package appchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		name      string
+		chainType string
+		ruleAddr  string
+	}{
+		{"fabric", "Fabric V1.4.3", fabricRuleAddr},
+		{"flato", "Flato V1.0.3", ""},
+		{"eth", "ETH", HappyRuleAddr},
+		{"hyperchain", "Hyperchain V1.8.3", HappyRuleAddr},
+		{"bcos", "BCOS V2.6.0", HappyRuleAddr},
+		{"fabric_mock", "Fabric V1.4.3", HappyRuleAddr},
+	}
+	require.Len(t, builtins, len(tests))
+	profiles := make(map[string]*Profile, len(builtins))
+	for _, p := range builtins {
+		profiles[p.Name] = p
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := profiles[tt.name]
+			require.True(t, ok)
+			require.NoError(t, p.validate())
+			require.Equal(t, tt.chainType, p.ChainType)
+			require.Equal(t, tt.ruleAddr, p.RuleAddr)
+		})
+	}
+}
@@ -6,13 +6,10 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"time"
 
-	"github.com/Rican7/retry"
-	"github.com/Rican7/retry/strategy"
 	appchainMgr "github.com/meshplus/bitxhub-core/appchain-mgr"
 	"github.com/meshplus/bitxhub-core/governance"
 	"github.com/meshplus/bitxhub-kit/crypto"
@@ -21,21 +18,41 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/appchain"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 )
 
 var maxDelay int64
 var counter int64
 var sender int64
+var failed int64
+var dropped int64
+var sendErrors = newErrorCounter()
 var delayer int64
+var delayed int64
 var ibtppd []byte
 
+// countCommitted counts a tx seen in a block, the soak recorder reads the counters concurrently
+func countCommitted() {
+	atomic.AddInt64(&counter, 1)
+}
+
+// countDelay counts the latency of a tx seen in a block
+func countDelay(delay int64) {
+	atomic.AddInt64(&delayed, 1)
+	atomic.AddInt64(&delayer, delay)
+}
+
 type bee struct {
 	normalPrivKey crypto.PrivateKey
 	toPrivKey     crypto.PrivateKey
 	normalFrom    *types.Address
 	normalTo      *types.Address
 	client        rpcx.Client
+	addr          string
 	tps           int
 	count         uint64
 	nonce         uint64
@@ -44,25 +61,37 @@ type bee struct {
 	cancel        context.CancelFunc
 	config        *Config
 	txs           chan *pb.MultiTransaction
+	stages        *stageTracker
+	statuses      *statusTracker
+	total         *totalTracker // nil unless the run sends an exact count of txs
+	quota         uint64        // txs the bee sends in an exact-count run
+	generated     uint64        // txs of the quota generated and not dropped
+	refill        chan struct{} // signals the quota is short of dropped txs
+	resyncs       chan struct{} // asks prepareTx to resync the nonce after a dropped batch
+	resynced      chan struct{} // answers resyncs
 }
 
 const (
 	Interchain = "interchain"
 	Data       = "data"
 	Transfer   = "transfer"
+	// Rollback sends interchain txs with short timeout heights and never sends their receipts
+	Rollback = "rollback"
 )
 
-type RegisterResult struct {
-	Extra      []byte `json:"extra"`
-	ProposalID string `json:"proposal_id"`
+// isInterchain reports whether txs of typ carry ibtps
+func isInterchain(typ string) bool {
+	return typ == Interchain || typ == Rollback
 }
 
-func NewBee(tps int, adminPk crypto.PrivateKey, adminFrom *types.Address, config *Config) (*bee, error) {
+type RegisterResult = admin.RegisterResult
+
+func NewBee(addr string, tps int, adminPk crypto.PrivateKey, adminFrom *types.Address, config *Config) (*bee, error) {
 	normalPk, normalFrom, err := repo.KeyPriv()
 	if err != nil {
 		return nil, err
 	}
-	node0 := &rpcx.NodeInfo{Addr: config.BitxhubAddr[0]}
+	node0 := &rpcx.NodeInfo{Addr: addr}
 
 	client, err := rpcx.New(
 		rpcx.WithNodesInfo(node0),
@@ -77,7 +106,7 @@ func NewBee(tps int, adminPk crypto.PrivateKey, adminFrom *types.Address, config
 	if err != nil {
 		return nil, err
 	}
-	err = TransferFromAdmin(client, adminPk, adminFrom, normalFrom, "100")
+	err = TransferFromAdmin(client, adminPk, adminFrom, normalFrom, BeeFund)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +125,7 @@ func NewBee(tps int, adminPk crypto.PrivateKey, adminFrom *types.Address, config
 		if err != nil {
 			return nil, err
 		}
-		err = TransferFromAdmin(client, adminPk, adminFrom, normalTo, "100")
+		err = TransferFromAdmin(client, adminPk, adminFrom, normalTo, BeeFund)
 		if err != nil {
 			return nil, err
 		}
@@ -105,6 +134,7 @@ func NewBee(tps int, adminPk crypto.PrivateKey, adminFrom *types.Address, config
 	ctx, cancel := context.WithCancel(context.Background())
 	return &bee{
 		client:        client,
+		addr:          addr,
 		normalPrivKey: normalPk,
 		toPrivKey:     toPK,
 		normalFrom:    normalFrom,
@@ -117,6 +147,9 @@ func NewBee(tps int, adminPk crypto.PrivateKey, adminFrom *types.Address, config
 		nonce:         nonce,
 		toNonce:       toNonce,
 		txs:           make(chan *pb.MultiTransaction, 1024),
+		refill:        make(chan struct{}, 1),
+		resyncs:       make(chan struct{}),
+		resynced:      make(chan struct{}),
 	}, nil
 }
 
@@ -127,15 +160,9 @@ func (bee *bee) start() error {
 		case <-bee.ctx.Done():
 			return nil
 		case txs := <-bee.txs:
-			err := retry.Retry(func(attempt uint) error {
-				_, err := bee.client.SendTransactions(txs)
-				if err != nil {
-					return err
-				}
+			bee.stages.dequeued(txs.Txs)
+			if !bee.send(txs) {
 				return nil
-			}, strategy.Wait(1*time.Second))
-			if err != nil {
-				return err
 			}
 		}
 	}
@@ -144,44 +171,117 @@ func (bee *bee) start() error {
 func (bee *bee) prepareTx(typ string) {
 	var count uint64
 	var nonce uint64
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+	pacer, err := newPacer(bee.config.Arrival, bee.tps)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	batchSize := bee.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
 
-	for {
+	var first time.Time
+	txs := make([]*pb.BxhTransaction, 0, batchSize)
+	// resync discards the batch being built along with the queued ones, their nonces are stale
+	resync := func() {
+		bee.discard(txs)
+		txs = make([]*pb.BxhTransaction, 0, batchSize)
+		bee.resyncNonce()
 		select {
 		case <-bee.ctx.Done():
+		case bee.resynced <- struct{}{}:
+		}
+	}
+	flush := func() bool {
+		select {
+		case <-bee.ctx.Done():
+			return false
+		case <-bee.resyncs:
+			resync()
+			return true
+		case bee.txs <- &pb.MultiTransaction{Txs: txs}:
+		}
+		txs = make([]*pb.BxhTransaction, 0, batchSize)
+		return true
+	}
+	timer := time.NewTimer(0)
+	<-timer.C
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-bee.resyncs:
+			resync()
+		default:
+		}
+		if bee.total != nil && atomic.LoadUint64(&bee.generated) >= bee.quota {
+			if len(txs) != 0 && !flush() {
+				return
+			}
+			// wait for dropped txs to be generated again
+			select {
+			case <-bee.ctx.Done():
+				return
+			case <-bee.resyncs:
+				resync()
+			case <-bee.refill:
+			}
+			continue
+		}
+		next := pacer.advance()
+		// do not hold the batch when the next tx is too far away
+		if len(txs) != 0 && next.Sub(first) > maxBatchDelay && !flush() {
 			return
-		case <-ticker.C:
-			txs := make([]*pb.BxhTransaction, 0)
-			for i := 0; i < bee.tps; i++ {
-				nonce = atomic.AddUint64(&bee.nonce, 1) - 1
-				if typ == Interchain {
-					count = atomic.AddUint64(&bee.count, 1) - 1
-				}
-				tx, err := bee.genTx(typ, nonce, count)
-				if err != nil {
-					panic(err)
-				}
-				txs = append(txs, tx)
-				if nonce%20 == 0 || (bee.tps-i) <= 20 {
-					bee.txs <- &pb.MultiTransaction{Txs: txs}
-					txs = make([]*pb.BxhTransaction, 0)
+		}
+		if wait := time.Until(next); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-bee.ctx.Done():
+				return
+			case <-bee.resyncs:
+				if !timer.Stop() {
+					<-timer.C
 				}
+				resync()
+			case <-timer.C:
 			}
+		} else if bee.ctx.Err() != nil {
+			return
+		}
+
+		nonce = atomic.AddUint64(&bee.nonce, 1) - 1
+		if isInterchain(typ) {
+			count = atomic.AddUint64(&bee.count, 1) - 1
+		}
+		tx, err := bee.genTx(typ, nonce, count)
+		if err != nil {
+			panic(err)
+		}
+		if bee.total != nil {
+			bee.total.add(tx)
+			atomic.AddUint64(&bee.generated, 1)
+		}
+		if len(txs) == 0 {
+			first = time.Now()
+		}
+		txs = append(txs, tx)
+		if len(txs) >= batchSize && !flush() {
+			return
 		}
 	}
 }
 
 func (bee *bee) genTx(typ string, nonce, count uint64) (*pb.BxhTransaction, error) {
 	switch typ {
-	case Interchain:
+	case Interchain, Rollback:
 		return bee.genInterchainTx(count, nonce)
 	case Data:
 		return bee.genBVMTx(nonce)
 	case Transfer:
 		fallthrough
 	default:
-		privKey, err := asym.GenerateKeyPair(crypto.Secp256k1)
+		privKey, err := asym.GenerateKeyPair(repo.KeyType())
 		if err != nil {
 			return nil, err
 		}
@@ -205,9 +305,12 @@ func (bee *bee) stop() error {
 }
 
 func (bee *bee) genBVMTx(nonce uint64) (*pb.BxhTransaction, error) {
-	atomic.AddInt64(&sender, 1)
+	value := "10"
+	if bee.config.PayloadSize > 0 {
+		value = strings.Repeat("a", bee.config.PayloadSize)
+	}
 	args := make([]*pb.Arg, 0)
-	args = append(args, rpcx.String("a"), rpcx.String("10"))
+	args = append(args, rpcx.String("a"), rpcx.String(value))
 
 	pl := &pb.InvokePayload{
 		Method: "Set",
@@ -241,28 +344,15 @@ func (bee *bee) genBVMTx(nonce uint64) (*pb.BxhTransaction, error) {
 	}
 	return tx, nil
 }
-func (bee *bee) prepareToChain(typ, desc string) error {
-	// register chain
-	broker := "0x857133c5C69e6Ce66F7AD46F200B9B3573e77582"
-	address := "0x00000000000000000000000000000000000000a2"
-	if typ == "Fabric V1.4.3" {
-		address = "0x00000000000000000000000000000000000000a0"
-		broker = "{\"channel_id\":\"mychannel\",\"chaincode_id\":\"broker\",\"broker_version\":\"1\"}"
-	} else if typ == "Flato V1.0.3" {
-		repoRoot, err := repo.PathRoot()
-		if err != nil {
-			return err
-		}
-		contract, err := ioutil.ReadFile(filepath.Join(repoRoot, "rule.wasm"))
-		if err != nil {
-			return fmt.Errorf("read rule file err %w", err)
-		}
-		addr, err := bee.client.DeployContract(contract, nil)
-		if err != nil {
-			return fmt.Errorf("deploy rule err %w", err)
-		}
-		atomic.AddUint64(&bee.toNonce, 1)
-		address = addr.String()
+func (bee *bee) prepareToChain(profile *appchain.Profile, desc string) error {
+	// register chain, a wasm rule is deployed by the key of the client
+	rule, deployed, err := profile.Rule(bee.client, nil)
+	if err != nil {
+		return err
+	}
+	if deployed {
+		atomic.AddUint64(&bee.nonce, 1)
+		record(manifest.Rule, manifest.RuleID(bee.normalTo.String(), rule), bee.toPrivKey)
 	}
 	bytes, err := bee.toPrivKey.PublicKey().Bytes()
 	if err != nil {
@@ -272,11 +362,11 @@ func (bee *bee) prepareToChain(typ, desc string) error {
 		rpcx.String(bee.normalTo.String()),       //chainID
 		rpcx.String(bee.normalTo.String()),       //chainName
 		rpcx.Bytes(bytes),                        //pubKey
-		rpcx.String(typ),                         //chainType
+		rpcx.String(profile.ChainType),           //chainType
 		rpcx.Bytes([]byte(bee.config.Validator)), //trustRoot
-		rpcx.String(broker),                      //broker
+		rpcx.String(profile.Broker),              //broker
 		rpcx.String(desc),                        //desc
-		rpcx.String(address),                     //masterRuleAddr
+		rpcx.String(rule),                        //masterRuleAddr
 		rpcx.String("https://github.com"),        //masterRuleUrl
 		rpcx.String(bee.normalTo.String()),       //adminAddrs
 		rpcx.String("reason"),                    //reason
@@ -289,6 +379,7 @@ func (bee *bee) prepareToChain(typ, desc string) error {
 	if err != nil {
 		return fmt.Errorf("register appchain error: %w", err)
 	}
+	record(manifest.Appchain, bee.normalTo.String(), bee.toPrivKey)
 	// vote chain
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
@@ -312,7 +403,7 @@ func (bee *bee) prepareToChain(typ, desc string) error {
 	//register server
 	args = []*pb.Arg{
 		rpcx.String(bee.normalTo.String()),
-		rpcx.String("mychannel&transfer"),
+		rpcx.String(service.DefaultServiceID),
 		rpcx.String(bee.normalTo.String()),
 		rpcx.String("CallContract"),
 		rpcx.String("test"),
@@ -329,6 +420,7 @@ func (bee *bee) prepareToChain(typ, desc string) error {
 	if err != nil {
 		return fmt.Errorf("register server error %w", err)
 	}
+	record(manifest.Service, service.NewBuilder("").Default(bee.normalTo.String()).ChainServiceID(), bee.toPrivKey)
 	//vote server
 	result = &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
@@ -342,29 +434,16 @@ func (bee *bee) prepareToChain(typ, desc string) error {
 	return nil
 }
 
-func (bee *bee) prepareChain(typ, desc string) error {
+func (bee *bee) prepareChain(profile *appchain.Profile, desc string) error {
 	bee.client.SetPrivateKey(bee.normalPrivKey)
 	// register chain
-	broker := "0x857133c5C69e6Ce66F7AD46F200B9B3573e77582"
-	address := "0x00000000000000000000000000000000000000a2"
-	if typ == "Fabric V1.4.3" {
-		address = "0x00000000000000000000000000000000000000a0"
-		broker = "{\"channel_id\":\"mychannel\",\"chaincode_id\":\"broker\",\"broker_version\":\"1\"}"
-	} else if typ == "Flato V1.0.3" {
-		repoRoot, err := repo.PathRoot()
-		if err != nil {
-			return err
-		}
-		contract, err := ioutil.ReadFile(filepath.Join(repoRoot, "rule.wasm"))
-		if err != nil {
-			return fmt.Errorf("read rule file err %w", err)
-		}
-		addr, err := bee.client.DeployContract(contract, nil)
-		if err != nil {
-			return fmt.Errorf("deploy rule err %w", err)
-		}
+	rule, deployed, err := profile.Rule(bee.client, nil)
+	if err != nil {
+		return err
+	}
+	if deployed {
 		atomic.AddUint64(&bee.nonce, 1)
-		address = addr.String()
+		record(manifest.Rule, manifest.RuleID(bee.normalFrom.String(), rule), bee.normalPrivKey)
 	}
 	bytes, err := bee.normalPrivKey.PublicKey().Bytes()
 	if err != nil {
@@ -374,11 +453,11 @@ func (bee *bee) prepareChain(typ, desc string) error {
 		rpcx.String(bee.normalFrom.String()),     //chainID
 		rpcx.String(bee.normalFrom.String()),     //chainName
 		rpcx.Bytes(bytes),                        //pubKey
-		rpcx.String(typ),                         //chainType
+		rpcx.String(profile.ChainType),           //chainType
 		rpcx.Bytes([]byte(bee.config.Validator)), //trustRoot
-		rpcx.String(broker),                      //broker
+		rpcx.String(profile.Broker),              //broker
 		rpcx.String(desc),                        //desc
-		rpcx.String(address),                     //masterRuleAddr
+		rpcx.String(rule),                        //masterRuleAddr
 		rpcx.String("https://github.com"),        //masterRuleUrl
 		rpcx.String(bee.normalFrom.String()),     //adminAddrs
 		rpcx.String("reason"),                    //reason
@@ -390,6 +469,7 @@ func (bee *bee) prepareChain(typ, desc string) error {
 	if err != nil {
 		return fmt.Errorf("register appchain error: %w", err)
 	}
+	record(manifest.Appchain, bee.normalFrom.String(), bee.normalPrivKey)
 	// vote chain
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
@@ -413,7 +493,7 @@ func (bee *bee) prepareChain(typ, desc string) error {
 	//register server
 	args = []*pb.Arg{
 		rpcx.String(bee.normalFrom.String()),
-		rpcx.String("mychannel&transfer"),
+		rpcx.String(service.DefaultServiceID),
 		rpcx.String(bee.normalFrom.String()),
 		rpcx.String("CallContract"),
 		rpcx.String("test"),
@@ -429,6 +509,7 @@ func (bee *bee) prepareChain(typ, desc string) error {
 	if err != nil {
 		return fmt.Errorf("register server error %w", err)
 	}
+	record(manifest.Service, service.NewBuilder("").Default(bee.normalFrom.String()).ChainServiceID(), bee.normalPrivKey)
 	atomic.AddUint64(&bee.nonce, 1)
 	//vote server
 	result = &RegisterResult{}
@@ -468,21 +549,21 @@ func (bee *bee) genTransferTx(to *types.Address, normalNo uint64) (*pb.BxhTransa
 }
 
 func (bee *bee) genInterchainTx(i, nonce uint64) (*pb.BxhTransaction, error) {
-	atomic.AddInt64(&sender, 1)
-	var to string
+	dest := To
 	if bee.config.MultiDestChain {
-		to = "1356:" + bee.normalTo.String() + ":mychannel&transfer"
-	} else {
-		to = "1356:" + To + ":mychannel&transfer"
+		dest = bee.normalTo.String()
 	}
+	from := service.NewBuilder(bee.config.BxhID).Default(bee.normalFrom.String())
+	to := service.NewBuilder(bee.config.destBxhID()).Default(dest)
 
-	ibtp := bee.mockIBTP(i, "1356:"+bee.normalFrom.String()+":mychannel&transfer", to, bee.config.Proof)
+	proof := bee.config.proof(i)
+	ibtp := bee.mockIBTP(i, from.String(), to.String(), proof)
 
 	tx := &pb.BxhTransaction{
 		From:      bee.normalFrom,
 		To:        constant.InterchainContractAddr.Address(),
 		Timestamp: time.Now().UnixNano(),
-		Extra:     bee.config.Proof,
+		Extra:     proof,
 		IBTP:      ibtp,
 		Nonce:     nonce,
 	}
@@ -530,59 +611,15 @@ func (bee *bee) mockIBTP(index uint64, from, to string, proof []byte) *pb.IBTP {
 }
 
 func (bee *bee) VotePass(client rpcx.Client, id string) error {
-	pk1, _, err := repo.Node1Priv()
-	if err != nil {
-		return err
-	}
-	res, err := bee.vote(client, pk1, atomic.AddUint64(&index1, 1), pb.String(id), pb.String("approve"), pb.String("Appchain Pass"))
-	if err != nil {
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-
-	pk2, _, err := repo.Node2Priv()
-	if err != nil {
-		return err
-	}
-	_, err = bee.vote(client, pk2, atomic.AddUint64(&index2, 1), pb.String(id), pb.String("approve"), pb.String("Appchain Pass"))
-	if err != nil {
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-
-	pk3, _, err := repo.Node3Priv()
-	if err != nil {
-		return err
-	}
-	res, err = bee.vote(client, pk3, atomic.AddUint64(&index3, 1), pb.String(id), pb.String("approve"), pb.String("Appchain Pass"))
-	if err != nil {
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-	return nil
-}
-
-func (bee *bee) vote(client rpcx.Client, key crypto.PrivateKey, index uint64, args ...*pb.Arg) (*pb.Receipt, error) {
-	address, err := key.PublicKey().Address()
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := client.InvokeBVMContract(constant.GovernanceContractAddr.Address(), "Vote", &rpcx.TransactOpts{
-		From:    address.String(),
-		Nonce:   index,
-		PrivKey: key,
-	}, args...)
-	if err != nil {
-		return nil, err
+	voter := admin.NewVoter(client, admins, "Appchain Pass")
+	// the funding admin may be in the set, share its nonce with TransferFromAdmin
+	voter.Nonce = func(a *admin.Admin) (uint64, error) {
+		if a.Address.String() == funder {
+			return atomic.AddUint64(&adminNonce, 1) - 1, nil
+		}
+		return a.NextNonce(client)
 	}
-	return res, nil
+	return voter.Vote(id, admin.Approve)
 }
 
 func (bee *bee) GetChainStatusById(client rpcx.Client, pk crypto.PrivateKey, id string) (*pb.Receipt, error) {
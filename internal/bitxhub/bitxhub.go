@@ -17,7 +17,14 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/appchain"
+	"github.com/meshplus/premo/internal/clock"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/reservoir"
+	"github.com/meshplus/premo/internal/service"
+	"github.com/meshplus/premo/internal/tps"
 	"github.com/sirupsen/logrus"
 	"github.com/wcharczuk/go-chart/v2"
 )
@@ -26,13 +33,12 @@ const (
 	loadFactor     = 10
 	ClientPoolSize = 4
 	MaxPoolSize    = 64
-	MaxBlockSize   = 2048
 )
 
-var index1 uint64
-var index2 uint64
-var index3 uint64
+var admins *admin.Set
 var adminNonce uint64
+var funder string              // address of the --key_path admin, its nonces come from adminNonce
+var created *manifest.Manifest // governed objects of the current run
 var log = logrus.New()
 var To string
 
@@ -40,16 +46,32 @@ type Broker struct {
 	config     *Config
 	bees       []*bee
 	client     rpcx.Client
+	adminFrom  *types.Address
 	adminNonce uint64
+	toKey      crypto.PrivateKey // key of the dest chain shared by the bees, nil with MultiDestChain
 	ctx        context.Context
 	cancel     context.CancelFunc
 	lock       sync.Mutex
+	finished   sync.Once
 
 	x          []time.Time
 	tpsY       []float64
 	latencyY   []float64
+	series     decimator // bounds x, tpsY and latencyY
 	maxTps     float64
 	maxLatency float64
+	latency    *reservoir.Latencies
+	blocks     *blockRecorder
+	stages     *stageTracker
+	clock      *clock.Estimator
+	nodes      map[[types.AddressLength]byte]string // bee address to its node
+	events     *eventRecorder
+	rollback   *rollbackTracker // nil unless the type is rollback
+	statuses   *statusTracker   // nil unless StatusSample is set for an interchain type
+	total      *totalTracker    // nil unless Total is set
+	soak       *soakRecorder    // nil unless SoakInterval is set
+	drain      time.Duration    // waited for the sent txs after the bees stopped
+	report     *Report
 }
 
 type Config struct {
@@ -58,13 +80,40 @@ type Config struct {
 	Duration       int // s uint
 	TimeoutHeight  int
 	Type           string
+	PayloadSize    int    // value size of data tx
+	Arrival        string // arrival process of txs: uniform, poisson
+	BatchSize      int    // txs sent by one SendTransactions
+	Retry          RetryPolicy
 	Validator      string
-	Proof          []byte
+	Proofs         [][]byte // proofs of interchain txs, cycled by ibtp index
 	KeyPath        string
 	BitxhubAddr    []string
-	Appchain       string
+	Appchain       *appchain.Profile
 	Graph          bool
 	MultiDestChain bool
+	ClockWarmup    int        // s uint, 0 disables clock offset correction
+	BxhID          string     // chain ID of the relay chain, empty for the default
+	DestBxhID      string     // chain ID of the relay chain of dest appchains, empty for BxhID
+	Crypto         string     // algorithm of bee accounts, empty for Secp256k1
+	SoakInterval   int        // s uint, 0 disables the rolling soak reports
+	SoakOutput     string     // file of the rolling soak reports, empty for DefaultSoakOutput
+	StatusSample   int        // one ibtp in every StatusSample is tracked until its final status, 0 disables it
+	Total          int        // exact count of txs to send, Duration bounds the wait for their commit, 0 sends for Duration
+	IgnoreFunds    bool       // warn instead of refusing a setup the admin or voters cannot afford
+	CollectFunds   bool       // send the balances of the bees back to the admin after the run
+	Collect        tps.Config // drain wait, warm-up and cool-down of the tps collection
+}
+
+// proof returns the proof of the interchain tx with ibtp index i
+func (c *Config) proof(i uint64) []byte {
+	return c.Proofs[i%uint64(len(c.Proofs))]
+}
+
+func (c *Config) destBxhID() string {
+	if c.DestBxhID == "" {
+		return c.BxhID
+	}
+	return c.DestBxhID
 }
 
 func calculateClientPoolSize(tps int) int {
@@ -76,13 +125,53 @@ func calculateClientPoolSize(tps int) int {
 	}
 	return poolSize
 }
+
+// resetCounters clears the statistics of the previous benchmark in the same process
+func resetCounters() {
+	atomic.StoreInt64(&maxDelay, 0)
+	atomic.StoreInt64(&counter, 0)
+	atomic.StoreInt64(&sender, 0)
+	atomic.StoreInt64(&failed, 0)
+	atomic.StoreInt64(&dropped, 0)
+	sendErrors.reset()
+	atomic.StoreInt64(&delayer, 0)
+	atomic.StoreInt64(&delayed, 0)
+}
+
+// record adds a created object to the manifest of the run, a failure only costs its cleanup
+func record(kind, id string, owner crypto.PrivateKey) {
+	if err := created.Record(kind, id, owner); err != nil {
+		log.WithField("error", err).Warnf("record %s %s", kind, id)
+	}
+}
+
 func New(config *Config) (*Broker, error) {
 	log.WithFields(logrus.Fields{
 		"concurrent": config.Concurrent,
 		"tps":        config.TPS,
 		"duration":   config.Duration,
 		"type":       config.Type,
+		"arrival":    config.Arrival,
+		"batch_size": config.BatchSize,
+		"crypto":     config.Crypto,
 	}).Info("Premo configuration")
+	if err := repo.SetKeyType(config.Crypto); err != nil {
+		return nil, err
+	}
+	if config.Retry == (RetryPolicy{}) {
+		config.Retry = DefaultRetryPolicy()
+	}
+	if err := config.Retry.Validate(); err != nil {
+		return nil, err
+	}
+	// a bee failing to pace would send nothing after the accounts are funded
+	if err := ValidateArrival(config.Arrival); err != nil {
+		return nil, err
+	}
+	if config.Type == Rollback && config.TimeoutHeight <= 0 {
+		config.TimeoutHeight = DefaultRollbackTimeout
+	}
+	resetCounters()
 	adminPk, err := asym.RestorePrivateKey(config.KeyPath, repo.KeyPassword)
 	if err != nil {
 		return nil, err
@@ -107,68 +196,61 @@ func New(config *Config) (*Broker, error) {
 		return nil, err
 	}
 
-	//query nodes nonce
-	_, node1Address, err := repo.Node1Priv()
-	if err != nil {
-		return nil, err
-	}
-	index1, err = client.GetPendingNonceByAccount(node1Address.String())
-	if err != nil {
-		return nil, err
-	}
-
-	_, node2Address, err := repo.Node2Priv()
-	if err != nil {
-		return nil, err
-	}
-	index2, err = client.GetPendingNonceByAccount(node2Address.String())
+	admins, err = admin.Load()
 	if err != nil {
 		return nil, err
 	}
 
-	_, node3Address, err := repo.Node3Priv()
-	if err != nil {
+	if err := checkFunds(client, adminFrom, config); err != nil {
 		return nil, err
 	}
-	index3, err = client.GetPendingNonceByAccount(node3Address.String())
+	created, err = manifest.Create(config.Type)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("create run manifest: %w", err)
 	}
-
-	index1 -= 1
-	index2 -= 1
-	index3 -= 1
+	log.Infof("run %s records the governed objects it creates, remove them with `premo cleanup --run %s`", created.ID, created.ID)
 
 	// query pending nonce for adminKey
 	adminNonce, err = client.GetPendingNonceByAccount(adminFrom.String())
 	if err != nil {
 		return nil, err
 	}
+	funder = adminFrom.String()
 	// prepare to
+	var toKey crypto.PrivateKey
 	if !config.MultiDestChain {
-		to, err := PrepareTo(client, config, adminPk, adminFrom)
+		to, key, err := PrepareTo(client, config, adminPk, adminFrom)
 		if err != nil {
 			return nil, err
 		}
 		To = to
+		toKey = key
+	}
+
+	var statuses *statusTracker
+	if config.StatusSample > 0 && isInterchain(config.Type) {
+		statuses = newStatusTracker(client, config.StatusSample)
 	}
 
 	var lock sync.Mutex
 	bees := make([]*bee, 0, config.Concurrent)
 	ctx, cancel := context.WithCancel(context.Background())
 
+	stages := newStageTracker()
 	pool := NewGoPool(MaxPoolSize)
 	var count uint64
 	for i := 0; i < config.Concurrent; i++ {
 		pool.Add()
-		go func(wg *Pool) {
+		go func(i int, wg *Pool) {
 			defer wg.Done()
-			bee, err := NewBee(config.TPS/config.Concurrent, adminPk, adminFrom, config)
+			bee, err := NewBee(config.BitxhubAddr[i%len(config.BitxhubAddr)], config.TPS/config.Concurrent, adminPk, adminFrom, config)
 			if err != nil {
 				log.Error("New bee: ", err.Error())
 				return
 			}
-			if config.Type == Interchain {
+			bee.stages = stages
+			bee.statuses = statuses
+			if isInterchain(config.Type) {
 				if err := bee.prepareChain(bee.config.Appchain, "fabric for law"); err != nil {
 					log.Error(err)
 					return
@@ -184,26 +266,71 @@ func New(config *Config) (*Broker, error) {
 			bees = append(bees, bee)
 			lock.Unlock()
 			log.Infof("prepared %d chain", atomic.AddUint64(&count, 1))
-		}(pool)
+		}(i, pool)
 	}
 
 	pool.Wait()
 	log.WithFields(logrus.Fields{
 		"number": len(bees),
 	}).Info("generate all bees")
+	var total *totalTracker
+	if config.Total > 0 {
+		if len(bees) == 0 {
+			cancel()
+			return nil, fmt.Errorf("no bee to send %d txs", config.Total)
+		}
+		total = newTotalTracker(config.Total)
+		for i, quota := range total.quotas(len(bees)) {
+			bees[i].total = total
+			bees[i].quota = quota
+		}
+		log.Infof("sending exactly %d txs, waiting up to %ds for them to be committed", config.Total, config.Duration)
+	}
+
+	var estimator *clock.Estimator
+	if config.ClockWarmup > 0 {
+		estimator = clock.NewEstimator(time.Duration(config.ClockWarmup) * time.Second)
+		log.Warnf("latencies are not measured in the first %ds while the clock offset to the nodes is estimated", config.ClockWarmup)
+	}
+	nodes := make(map[[types.AddressLength]byte]string, len(bees))
+	for _, bee := range bees {
+		nodes[bee.normalFrom.RawAddress] = bee.addr
+	}
+	var rollback *rollbackTracker
+	if config.Type == Rollback {
+		log.Infof("rollback workload withholds receipts of ibtps with timeout height %d", config.TimeoutHeight)
+		rollback = newRollbackTracker(client, config.TimeoutHeight)
+	}
+	var soak *soakRecorder
+	if config.SoakInterval > 0 {
+		soak = newSoakRecorder(client, time.Duration(config.SoakInterval)*time.Second, config.SoakOutput, stages.size)
+	}
 
 	return &Broker{
 		config:     config,
 		bees:       bees,
 		client:     client,
+		adminFrom:  adminFrom,
 		adminNonce: adminNonce,
+		toKey:      toKey,
 		ctx:        ctx,
 		cancel:     cancel,
+		latency:    reservoir.New(),
+		blocks:     newBlockRecorder(),
+		stages:     stages,
+		clock:      estimator,
+		events:     newEventRecorder(),
+		nodes:      nodes,
+		rollback:   rollback,
+		statuses:   statuses,
+		total:      total,
+		soak:       soak,
 	}, nil
 }
 
 func (b *Broker) Start() error {
 	log.Info("starting broker")
+	defer b.finish()
 	var wg sync.WaitGroup
 	wg.Add(len(b.bees))
 
@@ -236,9 +363,27 @@ func (b *Broker) Start() error {
 
 	// listen from bitxhub block
 	go b.listenBlock()
+	if b.rollback != nil {
+		go b.rollback.run(b.ctx)
+	}
+	if b.statuses != nil {
+		go b.statuses.run(b.ctx)
+	}
+	if b.soak != nil {
+		go b.soak.run(b.ctx)
+	}
+	if b.clock != nil {
+		for _, addr := range b.config.BitxhubAddr {
+			go b.estimateClock(addr)
+		}
+	}
 
 	time.Sleep(100 * time.Millisecond)
 	ticker := time.NewTicker(time.Duration(b.config.Duration) * time.Second)
+	var committed <-chan struct{}
+	if b.total != nil {
+		committed = b.total.done
+	}
 	select {
 	case <-b.ctx.Done():
 		if time.Since(current) < time.Duration(b.config.Duration) {
@@ -254,18 +399,36 @@ func (b *Broker) Start() error {
 		if err != nil {
 			return err
 		}
+	case <-committed:
+		log.Infof("all %d txs are committed", b.config.Total)
+		err = b.calTps(current, meta0)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// finish collects the funds and closes the run manifest once, whether the run ended,
+// failed or was stopped
+func (b *Broker) finish() {
+	b.finished.Do(func() {
+		if b.config.CollectFunds {
+			b.collect()
+		}
+		_ = created.Close()
+	})
+}
+
 func (b *Broker) listenBlock() {
 	var (
 		cnt  = int64(0)
+		lcnt = int64(0)
 		dly  = int64(0)
 		mDly = int64(0)
 	)
-	ch, err := b.client.Subscribe(context.TODO(), pb.SubscriptionRequest_BLOCK, nil)
+	ch, err := b.subscribeBlock(b.ctx)
 	if err != nil {
 		log.WithField("error", err).Error("subscribe block")
 		return
@@ -280,42 +443,81 @@ func (b *Broker) listenBlock() {
 			c := float64(cnt)
 			d := float64(dly) / float64(time.Millisecond)
 			md := float64(mDly) / float64(time.Millisecond)
-			log.Infof("current tps is %d, average tx delay is %fms, max tx delay is %fms", cnt, d/c, md)
+			avg := 0.0
+			if lcnt != 0 {
+				avg = d / float64(lcnt)
+			}
+			log.Infof("current tps is %d, average tx delay is %fms, max tx delay is %fms", cnt, avg, md)
 			if c == 0 {
 				continue
 			}
-			b.x = append(b.x, time.Now())
-			b.tpsY = append(b.tpsY, float64(cnt))
+			if b.series.keep() {
+				b.x = append(b.x, time.Now())
+				b.tpsY = append(b.tpsY, float64(cnt))
+				b.latencyY = append(b.latencyY, avg)
+				if b.series.full(len(b.x)) {
+					b.x = halveTimes(b.x)
+					b.tpsY = halve(b.tpsY)
+					b.latencyY = halve(b.latencyY)
+				}
+			}
 			if b.maxTps < float64(cnt) {
 				b.maxTps = float64(cnt)
 			}
-			b.latencyY = append(b.latencyY, d/c)
-			if b.maxLatency < d/c {
-				b.maxLatency = d / c
+			if b.maxLatency < avg {
+				b.maxLatency = avg
 			}
-			if maxDelay < mDly {
-				maxDelay = mDly
+			if atomic.LoadInt64(&maxDelay) < mDly {
+				atomic.StoreInt64(&maxDelay, mDly)
 			}
 
 			cnt = 0
+			lcnt = 0
 			dly = 0
 			mDly = 0
 
 		case data, ok := <-ch:
 			if !ok {
-				log.Warn("block subscription channel is closed")
 				return
 			}
 
-			block := data.(*pb.Block)
+			block := data.block
 			now := time.Now().UnixNano()
+			b.blocks.add(block)
+			if b.rollback != nil && block.BlockHeader != nil {
+				b.rollback.block(block.BlockHeader.Number, now)
+			}
+			if block.Transactions == nil {
+				continue
+			}
 			for _, tx := range block.Transactions.Transactions {
-				cnt++
-				counter++
+				countCommitted()
 
-				txDelay := now - tx.(*pb.BxhTransaction).ReceiveTimestamp
+				bxhTx := tx.(*pb.BxhTransaction)
+				if b.total != nil {
+					b.total.commit(bxhTx)
+				}
+				if _, ok := b.nodes[bxhTx.From.RawAddress]; ok && b.rollback != nil {
+					b.rollback.committed(bxhTx, block.BlockHeader.Number)
+				}
+				if data.backfilled {
+					// committed in total, but out of the per-second series and the latency
+					b.stages.drop(bxhTx)
+					continue
+				}
+				cnt++
+				b.stages.committed(bxhTx, now)
+				txDelay, ok := b.txDelay(bxhTx, now)
+				if !ok {
+					continue
+				}
+				lcnt++
+				countDelay(txDelay)
 				dly += txDelay
-				delayer += txDelay
+				b.latency.Add(time.Duration(txDelay))
+				if b.soak != nil {
+					b.soak.addLatency(txDelay)
+				}
 
 				if mDly < txDelay {
 					mDly = txDelay
@@ -325,47 +527,89 @@ func (b *Broker) listenBlock() {
 	}
 }
 
+// estimateClock estimates the clock offset to the node during the warm-up
+func (b *Broker) estimateClock(addr string) {
+	pk, _, err := repo.KeyPriv()
+	if err != nil {
+		log.WithField("error", err).Error("estimate clock")
+		return
+	}
+	client, err := rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: addr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		log.WithField("error", err).Error("estimate clock")
+		return
+	}
+	defer func() {
+		_ = client.Stop()
+	}()
+	if err := b.clock.Run(b.ctx, client, addr); err != nil {
+		log.WithField("error", err).Error("estimate clock")
+		return
+	}
+	if offset, ok := b.clock.Offset(addr); ok {
+		log.Infof("the clock offset to node %s is %s", addr, offset)
+	}
+}
+
+// txDelay returns the latency of tx from received by its node to seen in a block,
+// corrected by the clock offset to the node. It is false until the offset is estimated.
+func (b *Broker) txDelay(tx *pb.BxhTransaction, now int64) (int64, bool) {
+	delay := now - tx.ReceiveTimestamp
+	if b.clock == nil {
+		return delay, true
+	}
+	offset, ok := b.clock.Offset(b.nodes[tx.From.RawAddress])
+	if !ok {
+		return 0, false
+	}
+	return delay - int64(offset), true
+}
+
 func (b *Broker) calTps(current time.Time, meta0 *pb.ChainMeta) error {
-	_ = b.Stop(current)
+	// keep listening blocks until the sent txs are collected
+	b.lock.Lock()
+	b.stopBees(current)
 
 	meta1, err := b.client.GetChainMeta()
 	if err != nil {
 		return err
 	}
 	log.Info("Collecting tps info, please wait...")
-	time.Sleep(20 * time.Second)
-
-	skip := (meta1.Height - meta0.Height) / 8
-	begin := meta0.Height + skip
-	end := meta1.Height - skip
-
-	var (
-		tps      uint64
-		totalTps uint64
-		count    uint64
-		tmpBegin = begin
-	)
-
-	for tmpBegin < end {
-		if end-tmpBegin > MaxBlockSize {
-			tps, err = b.client.GetTPS(tmpBegin, tmpBegin+MaxBlockSize)
-			if err != nil {
-				return err
-			}
-			log.Infof("the TPS from block %d to %d is %d", tmpBegin, tmpBegin+MaxBlockSize, tps)
-		} else {
-			tps, err = b.client.GetTPS(tmpBegin, end)
-			if err != nil {
-				return err
-			}
-			log.Infof("the TPS from block %d to %d is %d", tmpBegin, end, tps)
+	end := meta1.Height
+	// an exact-count run has already waited for its txs
+	if b.total == nil {
+		drained := time.Now()
+		height, err := tps.Drain(b.client, &b.config.Collect, func() bool {
+			return b.stages.size() == 0
+		})
+		if err != nil {
+			log.WithField("error", err).Warn("drain the sent txs")
 		}
-		totalTps += tps
-		count++
-		tmpBegin = tmpBegin + MaxBlockSize
+		if height > end {
+			end = height
+		}
+		b.drain = time.Since(drained)
+		log.Infof("waited %s for the sent txs to drain", b.drain)
+	}
+	if b.rollback != nil {
+		// catch the statuses changed since the last poll
+		b.rollback.poll()
 	}
+	if b.statuses != nil {
+		b.statuses.poll()
+	}
+	b.cancel()
 
-	log.Infof("the total TPS from block %d to %d is %d", begin, end, totalTps/count)
+	stats, err := tps.Measure(b.client, meta0.Height, end, &b.config.Collect)
+	if err != nil {
+		return err
+	}
+	b.report = b.newReport(current, stats)
+	b.report.print()
 	err = b.client.Stop()
 	if err != nil {
 		return err
@@ -375,63 +619,131 @@ func (b *Broker) calTps(current time.Time, meta0 *pb.ChainMeta) error {
 		if err != nil {
 			return err
 		}
+		err = b.blocks.graph()
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+func (b *Broker) newReport(current time.Time, stats *tps.Stats) *Report {
+	report := &Report{
+		Duration:   time.Since(current),
+		Crypto:     repo.KeyTypeName(),
+		Sent:       atomic.LoadInt64(&sender),
+		Failed:     atomic.LoadInt64(&failed),
+		Dropped:    atomic.LoadInt64(&dropped),
+		SendErrors: sendErrors.snapshot(),
+		Committed:  atomic.LoadInt64(&counter),
+		TPS:        stats.Mean,
+		TPSStdDev:  stats.StdDev,
+		Windows:    stats,
+		Drain:      b.drain,
+		MaxLatency: time.Duration(atomic.LoadInt64(&maxDelay)),
+	}
+	if delayed := atomic.LoadInt64(&delayed); delayed != 0 {
+		report.AvgLatency = time.Duration(atomic.LoadInt64(&delayer) / delayed)
+	}
+	// only the bee txs count, other traffic of the chain would make up for lost ones
+	report.Missing = b.stages.missing()
+	ps := b.latency.Percentiles(50, 90, 99)
+	report.P50Latency, report.P90Latency, report.P99Latency = ps[0], ps[1], ps[2]
+	report.Block = b.blocks.summary()
+	report.Stage = b.stages.summary()
+	if b.clock != nil {
+		report.ClockOffsets = b.clock.Offsets()
+	}
+	report.Events = b.events.list()
+	if b.rollback != nil {
+		report.Rollback = b.rollback.summary()
+	}
+	if b.statuses != nil {
+		report.Status = b.statuses.summary()
+	}
+	if b.total != nil {
+		report.Total = b.total.summary()
+	}
+	if b.soak != nil {
+		report.Soak = b.soak.summary()
+	}
+	return report
+}
+
+// Report returns the summary of the finished benchmark, it is nil if the benchmark was interrupted
+func (b *Broker) Report() *Report {
+	return b.report
+}
+
 func (b *Broker) Stop(current time.Time) error {
 	// prevent stop function is repeatedly called
 	b.lock.Lock()
 	defer b.cancel()
+	b.stopBees(current)
+	b.finish()
+	return nil
+}
+
+func (b *Broker) stopBees(current time.Time) {
 	// wait for goroutines inside bees to stop
 	time.Sleep(1 * time.Second)
 
 	log.Info("Bees are quiting, please wait...")
 	for i := 0; i < len(b.bees); i++ {
-		err := b.bees[i].stop()
-		if err != nil {
-			return err
-		}
+		_ = b.bees[i].stop()
 	}
-	//err := b.client.Stop()
-	//if err != nil {
-	//	log.Warn(err)
-	//}
-	delayerAvg := float64(delayer) / float64(counter)
+	committed := atomic.LoadInt64(&counter)
+	delayerAvg := float64(atomic.LoadInt64(&delayer)) / float64(atomic.LoadInt64(&delayed))
 	log.WithFields(logrus.Fields{
-		"number":   counter,
+		"number":   committed,
 		"duration": time.Since(current).Seconds(),
-		"tps":      float64(counter) / time.Since(current).Seconds(),
+		"tps":      float64(committed) / time.Since(current).Seconds(),
 		"tx_delay": delayerAvg / float64(time.Millisecond),
 	}).Info("finish testing")
-	return nil
 }
 
-func PrepareTo(client *rpcx.ChainClient, config *Config, adminPk crypto.PrivateKey, adminFrom *types.Address) (string, error) {
+func PrepareTo(client *rpcx.ChainClient, config *Config, adminPk crypto.PrivateKey, adminFrom *types.Address) (string, crypto.PrivateKey, error) {
 	pk, from, err := repo.KeyPriv()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	bytes, err := pk.PublicKey().Bytes()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	err = TransferFromAdmin(client, adminPk, adminFrom, from, "100")
+	err = TransferFromAdmin(client, adminPk, adminFrom, from, BeeFund)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+	// a wasm rule is deployed by the new account, the nonce of admin stays untouched
+	toClient, err := rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: config.BitxhubAddr[0]}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	defer toClient.Stop()
+	rule, deployed, err := config.Appchain.Rule(toClient, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if deployed {
+		record(manifest.Rule, manifest.RuleID(from.String(), rule), pk)
 	}
 	args := []*pb.Arg{
-		rpcx.String(from.String()),                                //chainID
-		rpcx.String(from.String()),                                //chainName
-		rpcx.Bytes(bytes),                                         //pubKey
-		rpcx.String("Flato v1.0.3"),                               //chainType
-		rpcx.Bytes([]byte("")),                                    //trustRoot
-		rpcx.String("0x857133c5C69e6Ce66F7AD46F200B9B3573e77582"), //broker
-		rpcx.String("desc"),                                       //desc
-		rpcx.String("0x00000000000000000000000000000000000000a2"), //masterRuleAddr
-		rpcx.String("https://github.com"),                         //masterRuleUrl
-		rpcx.String(from.String()),                                //adminAddrs
-		rpcx.String("reason"),                                     //reason
+		rpcx.String(from.String()),             //chainID
+		rpcx.String(from.String()),             //chainName
+		rpcx.Bytes(bytes),                      //pubKey
+		rpcx.String(config.Appchain.ChainType), //chainType
+		rpcx.Bytes([]byte(config.Validator)),   //trustRoot
+		rpcx.String(config.Appchain.Broker),    //broker
+		rpcx.String("desc"),                    //desc
+		rpcx.String(rule),                      //masterRuleAddr
+		rpcx.String("https://github.com"),      //masterRuleUrl
+		rpcx.String(from.String()),             //adminAddrs
+		rpcx.String("reason"),                  //reason
 	}
 
 	res, err := client.InvokeBVMContract(constant.AppchainMgrContractAddr.Address(), "RegisterAppchain", &rpcx.TransactOpts{
@@ -440,33 +752,34 @@ func PrepareTo(client *rpcx.ChainClient, config *Config, adminPk crypto.PrivateK
 		PrivKey: pk,
 	}, args...)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
+	record(manifest.Appchain, from.String(), pk)
 	//vote chain
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil || result.ProposalID == "" {
-		return "", fmt.Errorf("vote chain unmarshal error: %w", err)
+		return "", nil, fmt.Errorf("vote chain unmarshal error: %w", err)
 	}
 	b := bee{}
 	b.config = config
 	err = b.VotePass(client, result.ProposalID)
 	if err != nil {
-		return "", fmt.Errorf("vote chain error: %w", err)
+		return "", nil, fmt.Errorf("vote chain error: %w", err)
 	}
 	res, err = b.GetChainStatusById(client, pk, from.String())
 	if err != nil {
-		return "", fmt.Errorf("getChainStatus error: %w", err)
+		return "", nil, fmt.Errorf("getChainStatus error: %w", err)
 	}
 	appchain := &appchain_mgr.Appchain{}
 	err = json.Unmarshal(res.Ret, appchain)
 	if err != nil || appchain.Status != governance.GovernanceAvailable {
-		return "", fmt.Errorf("chain error: %w", err)
+		return "", nil, fmt.Errorf("chain error: %w", err)
 	}
 	//register server
 	args = []*pb.Arg{
 		rpcx.String(from.String()),
-		rpcx.String("mychannel&transfer"),
+		rpcx.String(service.DefaultServiceID),
 		rpcx.String(from.String()),
 		rpcx.String("CallContract"),
 		rpcx.String("test"),
@@ -480,19 +793,20 @@ func PrepareTo(client *rpcx.ChainClient, config *Config, adminPk crypto.PrivateK
 		PrivKey: pk,
 	}, args...)
 	if err != nil {
-		return "", fmt.Errorf("register service error %w", err)
+		return "", nil, fmt.Errorf("register service error %w", err)
 	}
+	record(manifest.Service, service.NewBuilder("").Default(from.String()).ChainServiceID(), pk)
 	//vote server
 	result = &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil || result.ProposalID == "" {
-		return "", fmt.Errorf("vote server unmarshal error: %w", err)
+		return "", nil, fmt.Errorf("vote server unmarshal error: %w", err)
 	}
 	err = b.VotePass(client, result.ProposalID)
 	if err != nil {
-		return "", fmt.Errorf("vote server error: %w", err)
+		return "", nil, fmt.Errorf("vote server error: %w", err)
 	}
-	return from.String(), nil
+	return from.String(), pk, nil
 }
 
 func Graph(x []time.Time, tpsY []float64, latencyY []float64, maxTps, maxLatency float64) error {
@@ -0,0 +1,169 @@
+package bitxhub
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// BlockStats summarizes the blocks observed during a benchmark
+type BlockStats struct {
+	Blocks      uint64        `json:"blocks"`
+	EmptyBlocks uint64        `json:"empty_blocks"`
+	FirstHeight uint64        `json:"first_height"`
+	LastHeight  uint64        `json:"last_height"`
+	AvgInterval time.Duration `json:"avg_interval"`
+	MaxInterval time.Duration `json:"max_interval"`
+	AvgTxs      float64       `json:"avg_txs"`
+	MaxTxs      int           `json:"max_txs"`
+	AvgSize     float64       `json:"avg_size"`
+	MaxSize     int           `json:"max_size"`
+}
+
+// EmptyRatio returns the share of blocks without any tx
+func (s *BlockStats) EmptyRatio() float64 {
+	if s.Blocks == 0 {
+		return 0
+	}
+	return float64(s.EmptyBlocks) / float64(s.Blocks)
+}
+
+// blockRecorder records height, timestamp, tx count and size of every block
+type blockRecorder struct {
+	lock          sync.Mutex
+	stats         BlockStats
+	lastTimestamp int64
+	totalInterval int64
+	intervalCount int64
+	totalTxs      uint64
+	totalSize     uint64
+
+	heights   []float64
+	intervals []float64 // ms
+	txs       []float64
+	points    decimator // bounds heights, intervals and txs
+}
+
+func newBlockRecorder() *blockRecorder {
+	return &blockRecorder{}
+}
+
+func (r *blockRecorder) add(block *pb.Block) {
+	header := block.BlockHeader
+	if header == nil {
+		return
+	}
+	txs := 0
+	if block.Transactions != nil {
+		txs = len(block.Transactions.Transactions)
+	}
+	size := block.Size()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var interval int64
+	if r.stats.Blocks != 0 && header.Number == r.stats.LastHeight+1 {
+		interval = header.Timestamp - r.lastTimestamp
+		r.totalInterval += interval
+		r.intervalCount++
+		if time.Duration(interval) > r.stats.MaxInterval {
+			r.stats.MaxInterval = time.Duration(interval)
+		}
+	}
+	if r.stats.Blocks == 0 {
+		r.stats.FirstHeight = header.Number
+	}
+	r.stats.LastHeight = header.Number
+	r.lastTimestamp = header.Timestamp
+	r.stats.Blocks++
+	if txs == 0 {
+		r.stats.EmptyBlocks++
+	}
+	if txs > r.stats.MaxTxs {
+		r.stats.MaxTxs = txs
+	}
+	if size > r.stats.MaxSize {
+		r.stats.MaxSize = size
+	}
+	r.totalTxs += uint64(txs)
+	r.totalSize += uint64(size)
+
+	if !r.points.keep() {
+		return
+	}
+	r.heights = append(r.heights, float64(header.Number))
+	r.intervals = append(r.intervals, float64(interval)/float64(time.Millisecond))
+	r.txs = append(r.txs, float64(txs))
+	if r.points.full(len(r.heights)) {
+		r.heights = halve(r.heights)
+		r.intervals = halve(r.intervals)
+		r.txs = halve(r.txs)
+	}
+}
+
+func (r *blockRecorder) summary() *BlockStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	stats := r.stats
+	if r.intervalCount != 0 {
+		stats.AvgInterval = time.Duration(r.totalInterval / r.intervalCount)
+	}
+	if stats.Blocks != 0 {
+		stats.AvgTxs = float64(r.totalTxs) / float64(stats.Blocks)
+		stats.AvgSize = float64(r.totalSize) / float64(stats.Blocks)
+	}
+	return &stats
+}
+
+// graph draws txs per block and block interval by height into block.png
+func (r *blockRecorder) graph() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.heights) < 2 {
+		return nil
+	}
+	graph := chart.Chart{
+		XAxis: chart.XAxis{
+			Name: "Height",
+		},
+		YAxis: chart.YAxis{
+			Name: "Txs",
+		},
+		YAxisSecondary: chart.YAxis{
+			Name: "Interval(ms)",
+		},
+		Series: []chart.Series{
+			chart.ContinuousSeries{
+				Name:    "Txs per block",
+				XValues: r.heights,
+				YValues: r.txs,
+				Style: chart.Style{
+					StrokeColor: chart.GetDefaultColor(0).WithAlpha(64),
+					FillColor:   chart.GetDefaultColor(0).WithAlpha(64),
+				},
+			},
+			chart.ContinuousSeries{
+				Name:    "Block interval",
+				XValues: r.heights,
+				YValues: r.intervals,
+				YAxis:   chart.YAxisSecondary,
+				Style: chart.Style{
+					StrokeColor: chart.GetDefaultColor(1).WithAlpha(64),
+				},
+			},
+		},
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+	f, err := os.Create("block.png")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return graph.Render(chart.PNG, f)
+}
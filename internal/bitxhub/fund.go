@@ -0,0 +1,247 @@
+package bitxhub
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/sirupsen/logrus"
+)
+
+// BeeFund is the amount, in tokens, the admin transfers to every account it prepares
+const BeeFund = "100"
+
+// TxFee is the fee, in tokens, reserved for every tx of the setup, bitxhub does not tell
+// its gas price so it is a generous bound
+const TxFee = "1"
+
+// tokenDecimals is the number of decimals of a token
+const tokenDecimals = 18
+
+// tokens returns amount tokens in the smallest unit
+func tokens(amount string) *big.Int {
+	n, _ := new(big.Int).SetString(amount, 10)
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(tokenDecimals), nil)
+	return n.Mul(n, unit)
+}
+
+// FundedAccounts returns the number of accounts the admin funds before a run with
+// concurrent bees, the appchain every bee sends to is funded once unless every bee
+// has a destination chain of its own
+func FundedAccounts(concurrent int, multiDestChain bool) int {
+	if multiDestChain {
+		return 2 * concurrent
+	}
+	return concurrent + 1
+}
+
+// Budget returns the amount, in the smallest unit, the admin transfers before a run
+func Budget(concurrent int, multiDestChain bool) *big.Int {
+	budget := tokens(BeeFund)
+	return budget.Mul(budget, big.NewInt(int64(FundedAccounts(concurrent, multiDestChain))))
+}
+
+// Funding is what the setup of a benchmark costs, in the smallest unit
+type Funding struct {
+	Accounts  int      // accounts funded by the admin
+	Proposals int      // proposals approved by the voters
+	Admin     *big.Int // transfers of the admin and their fees
+	Voter     *big.Int // fees of every voter
+}
+
+// Estimate returns what the setup of a benchmark of config costs the admin and every voter
+// of admins.json, a voter votes every proposal at worst
+func Estimate(config *Config) *Funding {
+	f := &Funding{Accounts: FundedAccounts(config.Concurrent, config.MultiDestChain)}
+	if !config.MultiDestChain {
+		// the appchain and service of the dest chain
+		f.Proposals += 2
+	}
+	if isInterchain(config.Type) {
+		chains := 1
+		if config.MultiDestChain {
+			chains = 2
+		}
+		f.Proposals += 2 * chains * config.Concurrent
+	}
+	fee := tokens(TxFee)
+	f.Admin = Budget(config.Concurrent, config.MultiDestChain)
+	f.Admin.Add(f.Admin, new(big.Int).Mul(fee, big.NewInt(int64(f.Accounts))))
+	f.Voter = new(big.Int).Mul(fee, big.NewInt(int64(f.Proposals)))
+	return f
+}
+
+// Balance returns the balance, in the smallest unit, of address
+func Balance(client rpcx.Client, address string) (*big.Int, error) {
+	res, err := client.GetAccountBalance(address)
+	if err != nil {
+		return nil, err
+	}
+	account := &struct {
+		Balance *big.Int `json:"balance"`
+	}{}
+	if err := json.Unmarshal(res.Data, account); err != nil {
+		return nil, fmt.Errorf("unmarshal account %s: %w", address, err)
+	}
+	if account.Balance == nil {
+		return new(big.Int), nil
+	}
+	return account.Balance, nil
+}
+
+// CheckFunds compares the balances of the admin and the voters with funding, it returns
+// a description of every account that cannot afford its part
+func CheckFunds(client rpcx.Client, adminFrom *types.Address, admins *admin.Set, funding *Funding) ([]string, error) {
+	needs := map[string]*big.Int{adminFrom.String(): new(big.Int).Set(funding.Admin)}
+	order := []string{adminFrom.String()}
+	for _, a := range admins.Admins {
+		address := a.Address.String()
+		need, ok := needs[address]
+		if !ok {
+			need = new(big.Int)
+			needs[address] = need
+			order = append(order, address)
+		}
+		need.Add(need, funding.Voter)
+	}
+	var short []string
+	for _, address := range order {
+		balance, err := Balance(client, address)
+		if err != nil {
+			return nil, err
+		}
+		if need := needs[address]; balance.Cmp(need) < 0 {
+			short = append(short, fmt.Sprintf("%s has %s but needs %s", address, balance, need))
+		}
+	}
+	return short, nil
+}
+
+// checkFunds refuses a setup the admin or the voters cannot afford, unless told to go on
+func checkFunds(client rpcx.Client, adminFrom *types.Address, config *Config) error {
+	funding := Estimate(config)
+	log.WithFields(logrus.Fields{
+		"accounts":  funding.Accounts,
+		"proposals": funding.Proposals,
+		"admin":     funding.Admin.String(),
+		"voter":     funding.Voter.String(),
+	}).Info("setup funding")
+	short, err := CheckFunds(client, adminFrom, admins, funding)
+	if err != nil {
+		return err
+	}
+	if len(short) == 0 {
+		return nil
+	}
+	msg := "insufficient balance for the setup: " + strings.Join(short, "; ")
+	if config.IgnoreFunds {
+		log.Warn(msg)
+		return nil
+	}
+	return fmt.Errorf("%s, lower the concurrent or top up the accounts", msg)
+}
+
+// collect sends what is left on the accounts of the bees and of the shared dest chain back to the admin
+func (b *Broker) collect() {
+	log.Info("Collecting funds of bees, please wait...")
+	var (
+		lock   sync.Mutex
+		total  = new(big.Int)
+		failed int
+	)
+	pool := NewGoPool(MaxPoolSize)
+	for i := range b.bees {
+		pool.Add()
+		go func(i int) {
+			defer pool.Done()
+			amount, err := b.bees[i].collect(b.adminFrom)
+			lock.Lock()
+			defer lock.Unlock()
+			total.Add(total, amount)
+			if err != nil {
+				failed++
+				log.WithField("error", err).Warn("collect funds of bee")
+			}
+		}(i)
+	}
+	pool.Wait()
+	// the dest chain shared by the bees
+	if b.toKey != nil {
+		amount, err := sendBack(b.client, b.toKey, types.NewAddressByStr(To), b.adminFrom)
+		total.Add(total, amount)
+		if err != nil {
+			failed++
+			log.WithField("error", err).Warn("collect funds of dest chain")
+		}
+	}
+	log.WithFields(logrus.Fields{
+		"amount": total.String(),
+		"failed": failed,
+	}).Info("collected funds")
+}
+
+// collect sends the balances of the accounts of the bee to the admin, it returns the amount sent
+func (bee *bee) collect(adminFrom *types.Address) (*big.Int, error) {
+	total := new(big.Int)
+	accounts := []struct {
+		pk   crypto.PrivateKey
+		from *types.Address
+	}{{bee.normalPrivKey, bee.normalFrom}, {bee.toPrivKey, bee.normalTo}}
+	for _, account := range accounts {
+		if account.from == nil {
+			continue
+		}
+		amount, err := sendBack(bee.client, account.pk, account.from, adminFrom)
+		if err != nil {
+			return total, err
+		}
+		total.Add(total, amount)
+	}
+	return total, nil
+}
+
+// sendBack transfers the balance of from to the admin, less the fee of the transfer
+func sendBack(client rpcx.Client, pk crypto.PrivateKey, from, adminFrom *types.Address) (*big.Int, error) {
+	balance, err := Balance(client, from.String())
+	if err != nil {
+		return new(big.Int), err
+	}
+	amount := balance.Sub(balance, tokens(TxFee))
+	if amount.Sign() <= 0 {
+		return new(big.Int), nil
+	}
+	nonce, err := client.GetPendingNonceByAccount(from.String())
+	if err != nil {
+		return new(big.Int), err
+	}
+	data := &pb.TransactionData{Amount: amount.String()}
+	payload, err := data.Marshal()
+	if err != nil {
+		return new(big.Int), err
+	}
+	ret, err := client.SendTransactionWithReceipt(&pb.BxhTransaction{
+		From:      from,
+		To:        adminFrom,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	}, &rpcx.TransactOpts{
+		From:    from.String(),
+		Nonce:   nonce,
+		PrivKey: pk,
+	})
+	if err != nil {
+		return new(big.Int), err
+	}
+	if ret.Status != pb.Receipt_SUCCESS {
+		return new(big.Int), fmt.Errorf("send back funds of %s: %s", from.String(), string(ret.Ret))
+	}
+	return amount, nil
+}
@@ -0,0 +1,33 @@
+package bitxhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *Config
+		accounts  int
+		proposals int
+		admin     string // tokens
+		voter     string // tokens
+	}{
+		{"transfer", &Config{Type: Transfer, Concurrent: 4}, 5, 2, "505", "2"},
+		{"data to own chains", &Config{Type: Data, Concurrent: 2, MultiDestChain: true}, 4, 0, "404", "0"},
+		{"interchain", &Config{Type: Interchain, Concurrent: 4}, 5, 10, "505", "10"},
+		{"interchain to own chains", &Config{Type: Interchain, Concurrent: 4, MultiDestChain: true}, 8, 16, "808", "16"},
+		{"rollback", &Config{Type: Rollback, Concurrent: 1}, 2, 4, "202", "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Estimate(tt.config)
+			require.Equal(t, tt.accounts, f.Accounts)
+			require.Equal(t, tt.proposals, f.Proposals)
+			require.Equal(t, tokens(tt.admin).String(), f.Admin.String())
+			require.Equal(t, tokens(tt.voter).String(), f.Voter.String())
+		})
+	}
+}
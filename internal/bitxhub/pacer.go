@@ -0,0 +1,67 @@
+package bitxhub
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// arrival processes of txs generated by a bee
+const (
+	ArrivalUniform = "uniform"
+	ArrivalPoisson = "poisson"
+)
+
+const (
+	DefaultBatchSize = 20
+	// maxBatchDelay bounds how long a generated tx waits for its batch to fill
+	maxBatchDelay = 100 * time.Millisecond
+)
+
+// pacer schedules tx arrivals at a fixed rate, either evenly spaced or as a poisson process
+type pacer struct {
+	interval float64 // ns
+	poisson  bool
+	random   *rand.Rand
+	next     time.Time
+}
+
+func newPacer(arrival string, rate int) (*pacer, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("rate should be positive")
+	}
+	p := &pacer{
+		interval: float64(time.Second) / float64(rate),
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		next:     time.Now(),
+	}
+	if err := ValidateArrival(arrival); err != nil {
+		return nil, err
+	}
+	p.poisson = arrival == ArrivalPoisson
+	return p, nil
+}
+
+// ValidateArrival checks the arrival process of txs, empty is uniform
+func ValidateArrival(arrival string) error {
+	switch arrival {
+	case ArrivalUniform, ArrivalPoisson, "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported arrival process: %s", arrival)
+	}
+}
+
+// advance returns the time of the next arrival, a pacer behind schedule returns
+// times in the past so the missed arrivals catch up, but at most for one second
+func (p *pacer) advance() time.Time {
+	if time.Since(p.next) > time.Second {
+		p.next = time.Now()
+	}
+	d := p.interval
+	if p.poisson {
+		d = p.random.ExpFloat64() * p.interval
+	}
+	p.next = p.next.Add(time.Duration(d))
+	return p.next
+}
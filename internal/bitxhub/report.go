@@ -0,0 +1,220 @@
+package bitxhub
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/meshplus/premo/internal/clock"
+	"github.com/meshplus/premo/internal/reservoir"
+	"github.com/meshplus/premo/internal/tps"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxLatencySamples bounds the number of tx latencies kept for percentiles
+const MaxLatencySamples = reservoir.MaxSamples
+
+// Report is the summary of a finished benchmark
+type Report struct {
+	Duration   time.Duration  `json:"duration"`
+	Crypto     string         `json:"crypto"` // algorithm of bee accounts
+	Sent       int64          `json:"sent"`
+	Failed     int64          `json:"failed"`
+	Dropped    int64          `json:"dropped"`
+	Committed  int64          `json:"committed"` // txs in blocks, other traffic of the chain included
+	Missing    int64          `json:"missing"`   // txs sent by bees and not seen in blocks
+	TPS        float64        `json:"tps"`
+	TPSStdDev  float64        `json:"tps_stddev"` // across the windows of Windows
+	AvgLatency time.Duration  `json:"avg_latency"`
+	P50Latency time.Duration  `json:"p50_latency"`
+	P90Latency time.Duration  `json:"p90_latency"`
+	P99Latency time.Duration  `json:"p99_latency"`
+	MaxLatency time.Duration  `json:"max_latency"`
+	Drain      time.Duration  `json:"drain"` // waited for the sent txs after the bees stopped
+	Windows    *tps.Stats     `json:"windows"`
+	Block      *BlockStats    `json:"block"`
+	Stage      *StageStats    `json:"stage"`
+	Rollback   *RollbackStats `json:"rollback,omitempty"`
+	Status     *StatusStats   `json:"status,omitempty"`
+	Total      *TotalStats    `json:"total,omitempty"`
+	Soak       *SoakStats     `json:"soak,omitempty"`
+
+	SendErrors   map[string]int64 `json:"send_errors"` // failed txs per class of send error
+	ClockOffsets []*clock.Offset  `json:"clock_offsets"`
+	Events       []*Event         `json:"events"`
+}
+
+// FailureRatio returns the ratio of failed sends to all send attempts
+func (r *Report) FailureRatio() float64 {
+	total := r.Sent + r.Failed
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(total)
+}
+
+func (r *Report) print() {
+	log.WithFields(logrus.Fields{
+		"duration":      r.Duration.Seconds(),
+		"crypto":        r.Crypto,
+		"sent":          r.Sent,
+		"failed":        r.Failed,
+		"dropped":       r.Dropped,
+		"committed":     r.Committed,
+		"missing":       r.Missing,
+		"tps":           r.TPS,
+		"tps_stddev":    r.TPSStdDev,
+		"failure_ratio": r.FailureRatio(),
+		"avg_latency":   r.AvgLatency.String(),
+		"p50_latency":   r.P50Latency.String(),
+		"p90_latency":   r.P90Latency.String(),
+		"p99_latency":   r.P99Latency.String(),
+		"max_latency":   r.MaxLatency.String(),
+		"drain":         r.Drain.String(),
+	}).Info("benchmark report")
+	for _, class := range sortedClasses(r.SendErrors) {
+		log.WithFields(logrus.Fields{
+			"class": class,
+			"txs":   r.SendErrors[class],
+		}).Info("send error report")
+	}
+	if r.Block != nil {
+		log.WithFields(logrus.Fields{
+			"blocks":       r.Block.Blocks,
+			"heights":      fmt.Sprintf("%d-%d", r.Block.FirstHeight, r.Block.LastHeight),
+			"avg_interval": r.Block.AvgInterval.String(),
+			"max_interval": r.Block.MaxInterval.String(),
+			"avg_txs":      r.Block.AvgTxs,
+			"max_txs":      r.Block.MaxTxs,
+			"avg_size":     r.Block.AvgSize,
+			"max_size":     r.Block.MaxSize,
+			"empty_ratio":  r.Block.EmptyRatio(),
+		}).Info("block report")
+	}
+	if r.Stage != nil {
+		for _, stage := range []struct {
+			name    string
+			latency StageLatency
+		}{
+			{"queue", r.Stage.Queue},
+			{"send", r.Stage.Send},
+			{"commit", r.Stage.Commit},
+			{"total", r.Stage.Total},
+		} {
+			log.WithFields(logrus.Fields{
+				"stage":       stage.name,
+				"avg_latency": stage.latency.Avg.String(),
+				"p50_latency": stage.latency.P50.String(),
+				"p99_latency": stage.latency.P99.String(),
+			}).Info("stage report")
+		}
+		if r.Stage.Evicted != 0 || r.Stage.Untracked != 0 {
+			log.WithFields(logrus.Fields{
+				"evicted":   r.Stage.Evicted,
+				"untracked": r.Stage.Untracked,
+			}).Warn("stage report left out txs not seen in blocks")
+		}
+	}
+	if r.Rollback != nil {
+		log.WithFields(logrus.Fields{
+			"tracked":           r.Rollback.Tracked,
+			"timed_out":         r.Rollback.TimedOut,
+			"waiting":           r.Rollback.Waiting,
+			"unresolved":        r.Rollback.Unresolved,
+			"begin_rollback":    r.Rollback.BeginRollback,
+			"rollback":          r.Rollback.Rollback,
+			"other":             r.Rollback.Other,
+			"query_errors":      r.Rollback.QueryErrors,
+			"begin_avg_latency": r.Rollback.BeginLatency.Avg.String(),
+			"begin_p99_latency": r.Rollback.BeginLatency.P99.String(),
+			"final_avg_latency": r.Rollback.FinalLatency.Avg.String(),
+			"final_p99_latency": r.Rollback.FinalLatency.P99.String(),
+		}).Info("rollback report")
+	}
+	if r.Status != nil {
+		log.WithFields(logrus.Fields{
+			"sample":            r.Status.Sample,
+			"tracked":           r.Status.Tracked,
+			"unknown":           r.Status.Unknown,
+			"begin":             r.Status.Begin,
+			"begin_failure":     r.Status.BeginFailure,
+			"begin_rollback":    r.Status.BeginRollback,
+			"success":           r.Status.Success,
+			"failure":           r.Status.Failure,
+			"rollback":          r.Status.Rollback,
+			"query_errors":      r.Status.QueryErrors,
+			"final_avg_latency": r.Status.FinalLatency.Avg.String(),
+			"final_p50_latency": r.Status.FinalLatency.P50.String(),
+			"final_p99_latency": r.Status.FinalLatency.P99.String(),
+			"resolution":        r.Status.Resolution.String(),
+		}).Info("ibtp status report")
+	}
+	if r.Total != nil {
+		log.WithFields(logrus.Fields{
+			"total":     r.Total.Total,
+			"generated": r.Total.Generated,
+			"committed": r.Total.Committed,
+			"dropped":   r.Total.Dropped,
+			"missing":   len(r.Total.Missing),
+		}).Info("exact-count report")
+		for _, hash := range r.Total.Missing {
+			log.Warnf("tx %s is not committed", hash)
+		}
+	}
+	if r.Soak != nil {
+		r.Soak.print()
+	}
+	for _, offset := range r.ClockOffsets {
+		log.WithFields(logrus.Fields{
+			"node":    offset.Node,
+			"offset":  offset.Offset.String(),
+			"error":   offset.Error.String(),
+			"samples": offset.Samples,
+		}).Info("clock offset report")
+	}
+	for _, event := range r.Events {
+		log.WithFields(logrus.Fields{
+			"time":   event.Time.Format(time.RFC3339),
+			"type":   event.Type,
+			"detail": event.Detail,
+		}).Info("event report")
+	}
+}
+
+// types of events happened during a benchmark
+const (
+	EventDisconnect     = "disconnect"
+	EventReconnect      = "reconnect"
+	EventBackfill       = "backfill"
+	EventBackfillFailed = "backfill_failed"
+)
+
+// Event is something worth noting happened during a benchmark
+type Event struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Detail string    `json:"detail"`
+}
+
+type eventRecorder struct {
+	lock   sync.Mutex
+	events []*Event
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{}
+}
+
+func (r *eventRecorder) add(typ, detail string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, &Event{Time: time.Now(), Type: typ, Detail: detail})
+}
+
+func (r *eventRecorder) list() []*Event {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := make([]*Event, len(r.events))
+	copy(ret, r.events)
+	return ret
+}
@@ -0,0 +1,201 @@
+package bitxhub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/reservoir"
+)
+
+const (
+	// DefaultRollbackTimeout is the timeout height of the ibtps of the rollback workload
+	// if Config.TimeoutHeight is not set
+	DefaultRollbackTimeout = 5
+	// MaxRollbackTracked bounds the number of ibtps whose status is polled, the first ones are tracked
+	MaxRollbackTracked = 10000
+
+	rollbackPollInterval = time.Second
+	rollbackPollWorkers  = 8
+)
+
+// RollbackStats summarizes the ibtps of the rollback workload. An ibtp times out when the
+// block at its commit height plus its timeout height is seen, the latencies are from then
+// until its status is seen as BEGIN_ROLLBACK or ROLLBACK.
+type RollbackStats struct {
+	Tracked       int64        `json:"tracked"`
+	TimedOut      int64        `json:"timed_out"`      // the timeout height is reached
+	Waiting       int64        `json:"waiting"`        // the timeout height is not reached
+	Unresolved    int64        `json:"unresolved"`     // timed out but still BEGIN
+	BeginRollback int64        `json:"begin_rollback"` // seen BEGIN_ROLLBACK and not ROLLBACK yet
+	Rollback      int64        `json:"rollback"`
+	Other         int64        `json:"other"` // settled otherwise, e.g. BEGIN_FAILURE
+	QueryErrors   int64        `json:"query_errors"`
+	BeginLatency  StageLatency `json:"begin_latency"`
+	FinalLatency  StageLatency `json:"final_latency"`
+}
+
+type rollbackEntry struct {
+	id        string
+	deadline  uint64 // height of the block timing out the ibtp
+	timeoutAt int64
+	status    pb.TransactionStatus
+	settled   bool
+}
+
+// rollbackTracker follows the ibtps sent without receipts from commit to rollback
+type rollbackTracker struct {
+	client  rpcx.Client
+	timeout uint64
+
+	lock     sync.Mutex
+	tracked  int64
+	waiting  []*rollbackEntry // ordered by deadline
+	timedOut []*rollbackEntry
+	errors   int64
+
+	begin *reservoir.Latencies
+	final *reservoir.Latencies
+}
+
+func newRollbackTracker(client rpcx.Client, timeout int) *rollbackTracker {
+	return &rollbackTracker{
+		client:  client,
+		timeout: uint64(timeout),
+		begin:   reservoir.New(),
+		final:   reservoir.New(),
+	}
+}
+
+// committed is called when the block at height carrying the ibtp of tx is seen
+func (t *rollbackTracker) committed(tx *pb.BxhTransaction, height uint64) {
+	if tx.IBTP == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.tracked >= MaxRollbackTracked {
+		return
+	}
+	t.tracked++
+	t.waiting = append(t.waiting, &rollbackEntry{
+		id:       tx.IBTP.ID(),
+		deadline: height + t.timeout,
+		status:   pb.TransactionStatus_BEGIN,
+	})
+}
+
+// block is called when the block at height is seen, it times out the ibtps due at height
+func (t *rollbackTracker) block(height uint64, now int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	i := 0
+	for ; i < len(t.waiting) && t.waiting[i].deadline <= height; i++ {
+		t.waiting[i].timeoutAt = now
+	}
+	t.timedOut = append(t.timedOut, t.waiting[:i]...)
+	t.waiting = t.waiting[i:]
+}
+
+// run polls the status of timed out ibtps until ctx is done
+func (t *rollbackTracker) run(ctx context.Context) {
+	ticker := time.NewTicker(rollbackPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.poll()
+		}
+	}
+}
+
+// poll queries the status of every timed out ibtp not settled yet
+func (t *rollbackTracker) poll() {
+	t.lock.Lock()
+	entries := make([]*rollbackEntry, 0, len(t.timedOut))
+	for _, entry := range t.timedOut {
+		if !entry.settled {
+			entries = append(entries, entry)
+		}
+	}
+	t.lock.Unlock()
+
+	ch := make(chan *rollbackEntry)
+	var wg sync.WaitGroup
+	wg.Add(rollbackPollWorkers)
+	for i := 0; i < rollbackPollWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for entry := range ch {
+				status, err := ibtpStatus(t.client, entry.id)
+				if err != nil {
+					log.WithField("error", err).Debugf("get status of ibtp %s", entry.id)
+					t.lock.Lock()
+					t.errors++
+					t.lock.Unlock()
+					continue
+				}
+				t.update(entry, status, time.Now().UnixNano())
+			}
+		}()
+	}
+	for _, entry := range entries {
+		ch <- entry
+	}
+	close(ch)
+	wg.Wait()
+}
+
+func (t *rollbackTracker) update(entry *rollbackEntry, status pb.TransactionStatus, now int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if status == entry.status {
+		return
+	}
+	switch status {
+	case pb.TransactionStatus_BEGIN_ROLLBACK:
+		t.begin.Add(time.Duration(now - entry.timeoutAt))
+	case pb.TransactionStatus_ROLLBACK:
+		// BEGIN_ROLLBACK may be missed between two polls
+		if entry.status != pb.TransactionStatus_BEGIN_ROLLBACK {
+			t.begin.Add(time.Duration(now - entry.timeoutAt))
+		}
+		t.final.Add(time.Duration(now - entry.timeoutAt))
+		entry.settled = true
+	case pb.TransactionStatus_BEGIN:
+	default:
+		entry.settled = true
+	}
+	entry.status = status
+}
+
+func (t *rollbackTracker) summary() *RollbackStats {
+	t.lock.Lock()
+	stats := &RollbackStats{
+		Tracked:     t.tracked,
+		TimedOut:    int64(len(t.timedOut)),
+		Waiting:     int64(len(t.waiting)),
+		QueryErrors: t.errors,
+	}
+	for _, entry := range t.timedOut {
+		switch entry.status {
+		case pb.TransactionStatus_BEGIN:
+			stats.Unresolved++
+		case pb.TransactionStatus_BEGIN_ROLLBACK:
+			stats.BeginRollback++
+		case pb.TransactionStatus_ROLLBACK:
+			stats.Rollback++
+		default:
+			stats.Other++
+		}
+	}
+	t.lock.Unlock()
+
+	stats.BeginLatency = stageLatency(t.begin)
+	stats.FinalLatency = stageLatency(t.final)
+	return stats
+}
@@ -0,0 +1,257 @@
+package bitxhub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/sirupsen/logrus"
+)
+
+// classes of errors returned by SendTransactions
+const (
+	SendErrNonceTooLow         = "nonce_too_low"
+	SendErrNonceTooHigh        = "nonce_too_high"
+	SendErrInsufficientBalance = "insufficient_balance"
+	SendErrSignature           = "signature"
+	SendErrPoolFull            = "pool_full"
+	SendErrTransport           = "transport"
+	SendErrOther               = "other"
+)
+
+// what a bee does with a batch whose retries are exhausted
+const (
+	RetryDrop   = "drop"
+	RetryResync = "resync"
+)
+
+// RetryPolicy bounds how a bee retries a failed batch
+type RetryPolicy struct {
+	MaxAttempts int           // 0 retries forever
+	Backoff     time.Duration // wait before the first retry, doubled every retry
+	MaxBackoff  time.Duration
+	OnExhausted string // drop the batch, or drop it and resync the nonce from the node
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     500 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		OnExhausted: RetryResync,
+	}
+}
+
+// Validate checks the policy
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("max attempts should not be negative")
+	}
+	if p.Backoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("backoff should not be negative")
+	}
+	switch p.OnExhausted {
+	case RetryDrop, RetryResync:
+		return nil
+	default:
+		return fmt.Errorf("unsupported action on exhausted retries: %s", p.OnExhausted)
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// classifySendError maps the error of SendTransactions to its class by the messages
+// of bitxhub and the errors wrapped by go-bitxhub-client
+func classifySendError(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "nonce") &&
+		(strings.Contains(msg, "too low") || strings.Contains(msg, "lower") || strings.Contains(msg, "already")):
+		return SendErrNonceTooLow
+	case strings.Contains(msg, "nonce") &&
+		(strings.Contains(msg, "too high") || strings.Contains(msg, "higher") || strings.Contains(msg, "gap")):
+		return SendErrNonceTooHigh
+	case strings.Contains(msg, "insufficient") || strings.Contains(msg, "not enough") ||
+		strings.Contains(msg, "balance"):
+		return SendErrInsufficientBalance
+	case errors.Is(err, rpcx.ErrSignTx) || strings.Contains(msg, "signature"):
+		return SendErrSignature
+	// "context deadline exceeded" would be taken for a full pool below
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(msg, "deadline exceeded"):
+		return SendErrTransport
+	case strings.Contains(msg, "pool is full") || strings.Contains(msg, "pool full") ||
+		strings.Contains(msg, "exceed") || strings.Contains(msg, "busy"):
+		return SendErrPoolFull
+	case errors.Is(err, rpcx.ErrBrokenNetwork) || strings.Contains(msg, "connection") ||
+		strings.Contains(msg, "unavailable") || strings.Contains(msg, "transport"):
+		return SendErrTransport
+	default:
+		return SendErrOther
+	}
+}
+
+// retryable reports whether sending the same batch again may succeed
+func retryable(class string) bool {
+	switch class {
+	case SendErrNonceTooLow, SendErrSignature, SendErrInsufficientBalance:
+		return false
+	default:
+		return true
+	}
+}
+
+// errorCounter counts send errors per class
+type errorCounter struct {
+	lock   sync.Mutex
+	counts map[string]int64
+}
+
+func newErrorCounter() *errorCounter {
+	return &errorCounter{counts: make(map[string]int64)}
+}
+
+func (c *errorCounter) add(class string, n int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts[class] += n
+}
+
+func (c *errorCounter) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts = make(map[string]int64)
+}
+
+func (c *errorCounter) snapshot() map[string]int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ret := make(map[string]int64, len(c.counts))
+	for class, n := range c.counts {
+		ret[class] = n
+	}
+	return ret
+}
+
+// send sends the batch under the retry policy, it returns false if the bee is stopped
+func (bee *bee) send(txs *pb.MultiTransaction) bool {
+	policy := bee.config.Retry
+	n := int64(len(txs.Txs))
+	for attempt := 1; ; attempt++ {
+		_, err := bee.client.SendTransactions(txs)
+		if err == nil {
+			atomic.AddInt64(&sender, n)
+			bee.stages.sent(txs.Txs)
+			if bee.statuses != nil {
+				bee.statuses.sent(txs.Txs)
+			}
+			return true
+		}
+		class := classifySendError(err)
+		if !retryable(class) || (policy.MaxAttempts != 0 && attempt >= policy.MaxAttempts) {
+			// the txs are counted once, by the error that made the bee give them up
+			atomic.AddInt64(&failed, n)
+			sendErrors.add(class, n)
+			log.WithFields(logrus.Fields{
+				"class":    class,
+				"attempts": attempt,
+				"txs":      n,
+			}).Warnf("drop txs: %s", err)
+			bee.drop(txs)
+			return bee.ctx.Err() == nil
+		}
+		select {
+		case <-bee.ctx.Done():
+			return false
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+}
+
+// drop gives up the batch, with resync the following txs reuse the nonces from the
+// pending nonce of the node so they do not stall behind the gap. The resync is done by
+// prepareTx, the only generator of nonces, this waits until it is done.
+func (bee *bee) drop(txs *pb.MultiTransaction) {
+	bee.discard(txs.Txs)
+	if bee.config.Retry.OnExhausted != RetryResync {
+		return
+	}
+	select {
+	case <-bee.ctx.Done():
+		return
+	case bee.resyncs <- struct{}{}:
+	}
+	select {
+	case <-bee.ctx.Done():
+	case <-bee.resynced:
+	}
+}
+
+// discard counts txs as dropped
+func (bee *bee) discard(txs []*pb.BxhTransaction) {
+	atomic.AddInt64(&dropped, int64(len(txs)))
+	for _, tx := range txs {
+		bee.stages.drop(tx)
+	}
+	bee.requeue(txs)
+}
+
+// resyncNonce takes the pending nonce of the node, it is called by prepareTx while the sender
+// waits in drop, txs already queued carry stale nonces and are discarded
+func (bee *bee) resyncNonce() {
+	for drained := false; !drained; {
+		select {
+		case queued := <-bee.txs:
+			bee.discard(queued.Txs)
+		default:
+			drained = true
+		}
+	}
+	nonce, err := bee.client.GetPendingNonceByAccount(bee.normalFrom.String())
+	if err != nil {
+		log.WithField("error", err).Warn("resync nonce")
+		return
+	}
+	atomic.StoreUint64(&bee.nonce, nonce)
+}
+
+// requeue gives the dropped txs of an exact-count run back to the quota of the bee
+func (bee *bee) requeue(txs []*pb.BxhTransaction) {
+	if bee.total == nil || len(txs) == 0 {
+		return
+	}
+	for _, tx := range txs {
+		bee.total.drop(tx)
+	}
+	atomic.AddUint64(&bee.generated, ^uint64(len(txs)-1))
+	select {
+	case bee.refill <- struct{}{}:
+	default:
+	}
+}
+
+// sortedClasses returns the classes of counts in a stable order
+func sortedClasses(counts map[string]int64) []string {
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
@@ -0,0 +1,41 @@
+package bitxhub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifySendError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nonce too low", errors.New("the nonce of the tx is too low"), SendErrNonceTooLow},
+		{"nonce already used", errors.New("Nonce 3 already exists in pool"), SendErrNonceTooLow},
+		{"nonce too high", errors.New("nonce is higher than the pending nonce"), SendErrNonceTooHigh},
+		{"nonce gap", errors.New("nonce gap too large"), SendErrNonceTooHigh},
+		{"insufficient balance", errors.New("insufficient balance for gas"), SendErrInsufficientBalance},
+		{"not enough", errors.New("not enough value"), SendErrInsufficientBalance},
+		{"wrapped sign error", fmt.Errorf("send: %w", rpcx.ErrSignTx), SendErrSignature},
+		{"signature message", errors.New("invalid signature"), SendErrSignature},
+		{"pool full", errors.New("tx pool is full"), SendErrPoolFull},
+		{"node busy", errors.New("node is busy"), SendErrPoolFull},
+		{"broken network", fmt.Errorf("send: %w", rpcx.ErrBrokenNetwork), SendErrTransport},
+		{"deadline", fmt.Errorf("send: %w", context.DeadlineExceeded), SendErrTransport},
+		{"grpc deadline", errors.New("rpc error: code = DeadlineExceeded desc = context deadline exceeded"), SendErrTransport},
+		{"pool limit", errors.New("txs exceed the pool limit"), SendErrPoolFull},
+		{"grpc unavailable", errors.New("rpc error: code = Unavailable"), SendErrTransport},
+		{"unknown", errors.New("something else"), SendErrOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, classifySendError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package bitxhub
+
+import "time"
+
+// MaxSeriesPoints bounds the points kept for graphs, longer runs keep every other point
+// each time the bound is hit, so the graphs still cover the whole run
+const MaxSeriesPoints = 3600
+
+// decimator decides which points of a series are kept to stay within MaxSeriesPoints
+type decimator struct {
+	stride int
+	seen   int
+}
+
+// keep reports whether the next point of the series is kept
+func (d *decimator) keep() bool {
+	if d.stride == 0 {
+		d.stride = 1
+	}
+	d.seen++
+	return (d.seen-1)%d.stride == 0
+}
+
+// full reports whether a series of n points must be halved, it doubles the stride if so
+func (d *decimator) full(n int) bool {
+	if n < MaxSeriesPoints {
+		return false
+	}
+	d.stride *= 2
+	return true
+}
+
+// halve keeps the points at even indexes
+func halve(values []float64) []float64 {
+	kept := values[:0]
+	for i := 0; i < len(values); i += 2 {
+		kept = append(kept, values[i])
+	}
+	return kept
+}
+
+// halveTimes keeps the times at even indexes
+func halveTimes(times []time.Time) []time.Time {
+	kept := times[:0]
+	for i := 0; i < len(times); i += 2 {
+		kept = append(kept, times[i])
+	}
+	return kept
+}
@@ -0,0 +1,97 @@
+package bitxhub
+
+import (
+	"fmt"
+	"time"
+)
+
+// exit codes of failed assertions, several failures are or-ed together
+const (
+	ExitMinTPS          = 1 << 1
+	ExitMaxP99Latency   = 1 << 2
+	ExitMaxFailureRatio = 1 << 3
+	ExitMaxMissing      = 1 << 4
+	ExitLostTxs         = 1 << 5 // an exact-count run did not commit every tx
+	ExitNoReport        = 1 << 6 // the run or the sweep was interrupted before the report it was expected to give
+)
+
+// Assertions are the service level objectives checked at the end of a benchmark,
+// MinTPS and MaxP99Latency are disabled by zero, MaxFailureRatio and MaxMissing by a negative value
+type Assertions struct {
+	MinTPS          float64
+	MaxP99Latency   time.Duration
+	MaxFailureRatio float64
+	MaxMissing      int64
+}
+
+// AssertionFailure describes an assertion which does not hold for a report
+type AssertionFailure struct {
+	Name     string
+	Actual   string
+	Expected string
+	ExitCode int
+}
+
+func (f *AssertionFailure) String() string {
+	return fmt.Sprintf("assertion %s failed: got %s, want %s", f.Name, f.Actual, f.Expected)
+}
+
+// NewAssertions returns assertions with all checks disabled
+func NewAssertions() *Assertions {
+	return &Assertions{
+		MaxFailureRatio: -1,
+		MaxMissing:      -1,
+	}
+}
+
+// Enabled reports whether any assertion is enabled
+func (a *Assertions) Enabled() bool {
+	return a.MinTPS > 0 || a.MaxP99Latency > 0 || a.MaxFailureRatio >= 0 || a.MaxMissing >= 0
+}
+
+// Evaluate checks all enabled assertions against the report
+func (a *Assertions) Evaluate(r *Report) []*AssertionFailure {
+	var failures []*AssertionFailure
+	if a.MinTPS > 0 && r.TPS < a.MinTPS {
+		failures = append(failures, &AssertionFailure{
+			Name:     "min_tps",
+			Actual:   fmt.Sprintf("%.2f", r.TPS),
+			Expected: fmt.Sprintf(">= %.2f", a.MinTPS),
+			ExitCode: ExitMinTPS,
+		})
+	}
+	if a.MaxP99Latency > 0 && r.P99Latency > a.MaxP99Latency {
+		failures = append(failures, &AssertionFailure{
+			Name:     "max_p99_latency",
+			Actual:   r.P99Latency.String(),
+			Expected: fmt.Sprintf("<= %s", a.MaxP99Latency),
+			ExitCode: ExitMaxP99Latency,
+		})
+	}
+	if a.MaxFailureRatio >= 0 && r.FailureRatio() > a.MaxFailureRatio {
+		failures = append(failures, &AssertionFailure{
+			Name:     "max_failure_ratio",
+			Actual:   fmt.Sprintf("%.4f", r.FailureRatio()),
+			Expected: fmt.Sprintf("<= %.4f", a.MaxFailureRatio),
+			ExitCode: ExitMaxFailureRatio,
+		})
+	}
+	if r.Total != nil && r.Total.Committed < r.Total.Total {
+		failures = append(failures, &AssertionFailure{
+			Name: "total",
+			Actual: fmt.Sprintf("%d of %d committed, %d sent and missing hashes listed, %d never generated in time",
+				r.Total.Committed, r.Total.Total, len(r.Total.Missing), r.Total.Total-r.Total.Generated),
+			Expected: "all committed",
+			ExitCode: ExitLostTxs,
+		})
+	}
+	if a.MaxMissing >= 0 && r.Missing > a.MaxMissing {
+		failures = append(failures, &AssertionFailure{
+			Name:     "max_missing",
+			Actual:   fmt.Sprintf("%d", r.Missing),
+			Expected: fmt.Sprintf("<= %d", a.MaxMissing),
+			ExitCode: ExitMaxMissing,
+		})
+	}
+	return failures
+}
@@ -0,0 +1,318 @@
+package bitxhub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/reservoir"
+	"github.com/sirupsen/logrus"
+)
+
+// flags raised by the trends of a soak run
+const (
+	SoakTPSFalling     = "tps_falling"
+	SoakLatencyRising  = "latency_rising"
+	SoakFailuresRising = "failures_rising"
+	SoakPendingGrowing = "pending_growing"
+	SoakHeightStalled  = "height_stalled"
+)
+
+// DefaultSoakOutput is the file the rolling reports are appended to, one JSON per line
+const DefaultSoakOutput = "soak.jsonl"
+
+const (
+	soakWindow           = 6    // intervals the trends are fitted over
+	soakTPSDrop          = 0.1  // relative fall of tps over the window
+	soakLatencyRise      = 0.2  // relative rise of p99 latency over the window
+	soakFailureRise      = 0.01 // absolute rise of failure ratio over the window
+	soakPendingMinGrowth = 1000 // txs sent but not seen in blocks, growing every interval of the window
+)
+
+// SoakInterval is the rolling report of one interval of a soak run
+type SoakInterval struct {
+	Index        int           `json:"index"`
+	Start        time.Time     `json:"start"`
+	End          time.Time     `json:"end"`
+	Height       uint64        `json:"height"`
+	HeightGrowth uint64        `json:"height_growth"`
+	Sent         int64         `json:"sent"`
+	Failed       int64         `json:"failed"`
+	Committed    int64         `json:"committed"`
+	Pending      int           `json:"pending"` // txs sent but not seen in blocks, cumulative
+	TPS          float64       `json:"tps"`
+	FailureRatio float64       `json:"failure_ratio"`
+	P50Latency   time.Duration `json:"p50_latency"`
+	P90Latency   time.Duration `json:"p90_latency"`
+	P99Latency   time.Duration `json:"p99_latency"`
+	Flags        []string      `json:"flags,omitempty"`
+}
+
+// SoakStats summarizes the intervals of a soak run
+type SoakStats struct {
+	Intervals int            `json:"intervals"`
+	Flags     map[string]int `json:"flags"` // intervals raising each flag
+}
+
+type soakCounters struct {
+	time      time.Time
+	height    uint64
+	sent      int64
+	failed    int64
+	committed int64
+}
+
+// soakRecorder writes a rolling report every interval and flags the trends of
+// falling tps, rising latency or failures and growing pending txs
+type soakRecorder struct {
+	client   rpcx.Client
+	interval time.Duration
+	output   string
+	pending  func() int
+
+	lock    sync.Mutex
+	latency *reservoir.Latencies // of the current interval
+	last    soakCounters
+	window  []*SoakInterval // the last soakWindow intervals
+	index   int
+	flags   map[string]int
+}
+
+func newSoakRecorder(client rpcx.Client, interval time.Duration, output string, pending func() int) *soakRecorder {
+	if output == "" {
+		output = DefaultSoakOutput
+	}
+	return &soakRecorder{
+		client:   client,
+		interval: interval,
+		output:   output,
+		pending:  pending,
+		latency:  reservoir.New(),
+		flags:    make(map[string]int),
+	}
+}
+
+func (s *soakRecorder) addLatency(latency int64) {
+	s.lock.Lock()
+	recorder := s.latency
+	s.lock.Unlock()
+	recorder.Add(time.Duration(latency))
+}
+
+// run writes a rolling report every interval until ctx is done
+func (s *soakRecorder) run(ctx context.Context) {
+	counters, err := s.counters()
+	if err != nil {
+		log.WithField("error", err).Error("start soak report")
+		return
+	}
+	s.last = counters
+	f, err := os.OpenFile(s.output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.WithField("error", err).Error("open soak report")
+		return
+	}
+	defer f.Close()
+	log.Infof("soak reports are written to %s every %s", s.output, s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		interval, err := s.rotate()
+		if err != nil {
+			log.WithField("error", err).Error("soak report")
+			continue
+		}
+		interval.print()
+		data, err := json.Marshal(interval)
+		if err != nil {
+			log.WithField("error", err).Error("marshal soak report")
+			continue
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			log.WithField("error", err).Error("write soak report")
+		}
+	}
+}
+
+func (s *soakRecorder) counters() (soakCounters, error) {
+	meta, err := s.client.GetChainMeta()
+	if err != nil {
+		return soakCounters{}, err
+	}
+	return soakCounters{
+		time:      time.Now(),
+		height:    meta.Height,
+		sent:      atomic.LoadInt64(&sender),
+		failed:    atomic.LoadInt64(&failed),
+		committed: atomic.LoadInt64(&counter),
+	}, nil
+}
+
+// rotate closes the current interval and starts the next one
+func (s *soakRecorder) rotate() (*SoakInterval, error) {
+	counters, err := s.counters()
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	latency := s.latency
+	s.latency = reservoir.New()
+	s.index++
+	index := s.index
+	s.lock.Unlock()
+
+	last := s.last
+	s.last = counters
+	interval := &SoakInterval{
+		Index:     index,
+		Start:     last.time,
+		End:       counters.time,
+		Height:    counters.height,
+		Sent:      counters.sent - last.sent,
+		Failed:    counters.failed - last.failed,
+		Committed: counters.committed - last.committed,
+		Pending:   s.pending(),
+	}
+	if counters.height > last.height {
+		interval.HeightGrowth = counters.height - last.height
+	}
+	if seconds := counters.time.Sub(last.time).Seconds(); seconds > 0 {
+		interval.TPS = float64(interval.Committed) / seconds
+	}
+	if total := interval.Sent + interval.Failed; total != 0 {
+		interval.FailureRatio = float64(interval.Failed) / float64(total)
+	}
+	ps := latency.Percentiles(50, 90, 99)
+	interval.P50Latency, interval.P90Latency, interval.P99Latency = ps[0], ps[1], ps[2]
+
+	s.window = append(s.window, interval)
+	if len(s.window) > soakWindow {
+		s.window = s.window[1:]
+	}
+	interval.Flags = s.trends()
+	s.lock.Lock()
+	for _, flag := range interval.Flags {
+		s.flags[flag]++
+	}
+	s.lock.Unlock()
+	return interval, nil
+}
+
+// trends flags the degradation over the window ending with the current interval
+func (s *soakRecorder) trends() []string {
+	var flags []string
+	current := s.window[len(s.window)-1]
+	if current.HeightGrowth == 0 {
+		flags = append(flags, SoakHeightStalled)
+	}
+	if len(s.window) < 3 {
+		return flags
+	}
+	tps := make([]float64, len(s.window))
+	latency := make([]float64, len(s.window))
+	failures := make([]float64, len(s.window))
+	growing := true
+	for i, interval := range s.window {
+		tps[i] = interval.TPS
+		latency[i] = float64(interval.P99Latency)
+		failures[i] = interval.FailureRatio
+		if i > 0 && interval.Pending <= s.window[i-1].Pending {
+			growing = false
+		}
+	}
+	if change, mean := trend(tps); mean > 0 && change/mean < -soakTPSDrop {
+		flags = append(flags, SoakTPSFalling)
+	}
+	if change, mean := trend(latency); mean > 0 && change/mean > soakLatencyRise {
+		flags = append(flags, SoakLatencyRising)
+	}
+	if change, _ := trend(failures); change > soakFailureRise {
+		flags = append(flags, SoakFailuresRising)
+	}
+	if growing && current.Pending-s.window[0].Pending >= soakPendingMinGrowth {
+		flags = append(flags, SoakPendingGrowing)
+	}
+	return flags
+}
+
+// trend fits a line to the values by least squares and returns its change
+// from the first to the last value together with the mean of the values
+func trend(values []float64) (change, mean float64) {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	mean = sumY / n
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, mean
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return slope * (n - 1), mean
+}
+
+func (s *soakRecorder) summary() *SoakStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	flags := make(map[string]int, len(s.flags))
+	for flag, count := range s.flags {
+		flags[flag] = count
+	}
+	return &SoakStats{Intervals: s.index, Flags: flags}
+}
+
+func (i *SoakInterval) print() {
+	entry := log.WithFields(logrus.Fields{
+		"index":         i.Index,
+		"height":        i.Height,
+		"height_growth": i.HeightGrowth,
+		"sent":          i.Sent,
+		"failed":        i.Failed,
+		"committed":     i.Committed,
+		"pending":       i.Pending,
+		"tps":           i.TPS,
+		"failure_ratio": i.FailureRatio,
+		"p50_latency":   i.P50Latency.String(),
+		"p90_latency":   i.P90Latency.String(),
+		"p99_latency":   i.P99Latency.String(),
+	})
+	if len(i.Flags) == 0 {
+		entry.Info("soak report")
+		return
+	}
+	entry.Warnf("soak report, possible leak or degradation: %s", strings.Join(i.Flags, ", "))
+}
+
+func (s *SoakStats) print() {
+	flags := make([]string, 0, len(s.Flags))
+	for flag := range s.Flags {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	summary := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		summary = append(summary, fmt.Sprintf("%s=%d", flag, s.Flags[flag]))
+	}
+	log.WithFields(logrus.Fields{
+		"intervals": s.Intervals,
+		"flags":     strings.Join(summary, " "),
+	}).Info("soak summary")
+}
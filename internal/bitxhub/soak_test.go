@@ -0,0 +1,70 @@
+package bitxhub
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/stretchr/testify/require"
+)
+
+// metaClient serves a chain height growing by one every call
+type metaClient struct {
+	rpcx.Client
+	height uint64
+}
+
+func (c *metaClient) GetChainMeta() (*pb.ChainMeta, error) {
+	return &pb.ChainMeta{Height: atomic.AddUint64(&c.height, 1)}, nil
+}
+
+// TestSoakRotateWhileCounting rotates the soak intervals while txs are counted, run with -race
+func TestSoakRotateWhileCounting(t *testing.T) {
+	atomic.StoreInt64(&counter, 0)
+	atomic.StoreInt64(&delayed, 0)
+	atomic.StoreInt64(&delayer, 0)
+
+	s := newSoakRecorder(&metaClient{}, time.Hour, filepath.Join(t.TempDir(), "soak.jsonl"), func() int { return 0 })
+	start, err := s.counters()
+	require.NoError(t, err)
+	s.last = start
+
+	const workers, txs = 4, 1000
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < txs; j++ {
+				countCommitted()
+				countDelay(int64(time.Millisecond))
+				s.addLatency(int64(time.Millisecond))
+			}
+		}()
+	}
+	var committed int64
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for rotating := true; rotating; {
+		select {
+		case <-done:
+			rotating = false
+		default:
+		}
+		interval, err := s.rotate()
+		require.NoError(t, err)
+		committed += interval.Committed
+	}
+
+	require.Equal(t, int64(workers*txs), committed)
+	require.Equal(t, int64(workers*txs), atomic.LoadInt64(&delayed))
+	require.Equal(t, int64(workers*txs)*int64(time.Millisecond), atomic.LoadInt64(&delayer))
+	require.Equal(t, s.index, s.summary().Intervals)
+}
@@ -0,0 +1,191 @@
+package bitxhub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/meshplus/premo/internal/reservoir"
+)
+
+const (
+	// MaxStagePending bounds the number of txs timestamped while not seen in blocks
+	MaxStagePending = 1000000
+	// StageMaxAge is how long a tx not seen in blocks stays timestamped once the bound is reached
+	StageMaxAge = 10 * time.Minute
+
+	stageEvictInterval = time.Minute
+)
+
+// StageLatency is the latency of one stage of a tx
+type StageLatency struct {
+	Avg time.Duration `json:"avg"`
+	P50 time.Duration `json:"p50"`
+	P99 time.Duration `json:"p99"`
+}
+
+// StageStats breaks the latency of txs down by stage:
+// queue is from generated to dequeued by the bee, send is the SendTransactions call,
+// commit is from sent to the block seen, total is from generated to the block seen.
+// Evicted txs were forgotten before their block was seen, Untracked were never timestamped
+// because MaxStagePending was reached, so they are not counted as missing either.
+type StageStats struct {
+	Queue     StageLatency `json:"queue"`
+	Send      StageLatency `json:"send"`
+	Commit    StageLatency `json:"commit"`
+	Total     StageLatency `json:"total"`
+	Evicted   int64        `json:"evicted"`
+	Untracked int64        `json:"untracked"`
+}
+
+type txKey struct {
+	from  [types.AddressLength]byte
+	nonce uint64
+}
+
+type txStages struct {
+	generated int64
+	dequeued  int64
+	sent      int64
+}
+
+// stageTracker timestamps every tx sent by bees until its block is seen, once MaxStagePending
+// txs are pending the ones older than StageMaxAge are evicted
+type stageTracker struct {
+	lock      sync.Mutex
+	pending   map[txKey]*txStages
+	evicted   int64
+	lost      int64 // evicted after they were sent
+	untracked int64
+	swept     int64
+
+	queue  *reservoir.Latencies
+	send   *reservoir.Latencies
+	commit *reservoir.Latencies
+	total  *reservoir.Latencies
+}
+
+func newStageTracker() *stageTracker {
+	return &stageTracker{
+		pending: make(map[txKey]*txStages),
+		queue:   reservoir.New(),
+		send:    reservoir.New(),
+		commit:  reservoir.New(),
+		total:   reservoir.New(),
+	}
+}
+
+func keyOf(tx *pb.BxhTransaction) txKey {
+	return txKey{from: tx.From.RawAddress, nonce: tx.Nonce}
+}
+
+// dequeued is called when a bee takes txs from its queue
+func (t *stageTracker) dequeued(txs []*pb.BxhTransaction) {
+	now := time.Now().UnixNano()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, tx := range txs {
+		if len(t.pending) >= MaxStagePending && !t.evict(now) {
+			t.untracked++
+			continue
+		}
+		t.pending[keyOf(tx)] = &txStages{generated: tx.Timestamp, dequeued: now}
+	}
+}
+
+// evict forgets the txs dequeued more than StageMaxAge before now, at most once every
+// stageEvictInterval, it reports whether room was made. It is called with the lock held.
+func (t *stageTracker) evict(now int64) bool {
+	if now-t.swept < int64(stageEvictInterval) {
+		return false
+	}
+	t.swept = now
+	for key, stages := range t.pending {
+		if now-stages.dequeued > int64(StageMaxAge) {
+			delete(t.pending, key)
+			t.evicted++
+			if stages.sent != 0 {
+				t.lost++
+			}
+		}
+	}
+	return len(t.pending) < MaxStagePending
+}
+
+// sent is called when SendTransactions returns successfully
+func (t *stageTracker) sent(txs []*pb.BxhTransaction) {
+	now := time.Now().UnixNano()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, tx := range txs {
+		if stages, ok := t.pending[keyOf(tx)]; ok {
+			stages.sent = now
+		}
+	}
+}
+
+// committed is called when the block of tx is seen
+func (t *stageTracker) committed(tx *pb.BxhTransaction, now int64) {
+	key := keyOf(tx)
+	t.lock.Lock()
+	stages, ok := t.pending[key]
+	if ok {
+		delete(t.pending, key)
+	}
+	t.lock.Unlock()
+	if !ok || stages.sent == 0 {
+		return
+	}
+
+	t.queue.Add(time.Duration(stages.dequeued - stages.generated))
+	t.send.Add(time.Duration(stages.sent - stages.dequeued))
+	t.commit.Add(time.Duration(now - stages.sent))
+	t.total.Add(time.Duration(now - stages.generated))
+}
+
+// size returns the number of txs sent but not seen in blocks
+func (t *stageTracker) size() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return len(t.pending)
+}
+
+// missing returns the number of txs sent but not seen in blocks, the evicted ones included
+func (t *stageTracker) missing() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	n := t.lost
+	for _, stages := range t.pending {
+		if stages.sent != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// drop forgets tx whose commit time is unknown
+func (t *stageTracker) drop(tx *pb.BxhTransaction) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, keyOf(tx))
+}
+
+func (t *stageTracker) summary() *StageStats {
+	t.lock.Lock()
+	evicted, untracked := t.evicted, t.untracked
+	t.lock.Unlock()
+	return &StageStats{
+		Queue:     stageLatency(t.queue),
+		Send:      stageLatency(t.send),
+		Commit:    stageLatency(t.commit),
+		Total:     stageLatency(t.total),
+		Evicted:   evicted,
+		Untracked: untracked,
+	}
+}
+
+func stageLatency(r *reservoir.Latencies) StageLatency {
+	ps := r.Percentiles(50, 99)
+	return StageLatency{Avg: r.Mean(), P50: ps[0], P99: ps[1]}
+}
@@ -0,0 +1,58 @@
+package bitxhub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func stageTxs(from byte, n int) []*pb.BxhTransaction {
+	addr := types.NewAddress([]byte{from})
+	txs := make([]*pb.BxhTransaction, n)
+	for i := range txs {
+		txs[i] = &pb.BxhTransaction{From: addr, Nonce: uint64(i), Timestamp: time.Now().UnixNano()}
+	}
+	return txs
+}
+
+func TestStageTrackerMissing(t *testing.T) {
+	tests := []struct {
+		name      string
+		sent      int // of 4 dequeued bee txs
+		committed int // of the sent ones
+		others    int // txs of other accounts in blocks
+		evict     bool
+		want      int64
+	}{
+		{"all committed", 4, 4, 0, false, 0},
+		{"lost", 4, 1, 0, false, 3},
+		{"other traffic does not make up for lost txs", 4, 1, 3, false, 3},
+		{"not sent yet", 2, 2, 0, false, 0},
+		{"evicted after sent", 4, 1, 0, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newStageTracker()
+			txs := stageTxs(1, 4)
+			tracker.dequeued(txs)
+			tracker.sent(txs[:tt.sent])
+			now := time.Now().UnixNano()
+			for _, tx := range txs[:tt.committed] {
+				tracker.committed(tx, now)
+			}
+			for _, tx := range stageTxs(2, tt.others) {
+				tracker.committed(tx, now)
+			}
+			if tt.evict {
+				tracker.lock.Lock()
+				require.True(t, tracker.evict(now+int64(StageMaxAge)+1))
+				tracker.lock.Unlock()
+				require.Equal(t, 0, tracker.size())
+			}
+			require.Equal(t, tt.want, tracker.missing())
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package bitxhub
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/reservoir"
+)
+
+const (
+	// MaxStatusTracked bounds the number of ibtps whose final status is followed, the first sampled ones are tracked
+	MaxStatusTracked = 10000
+
+	statusPollInterval = time.Second
+	statusPollWorkers  = 8
+)
+
+// StatusStats is the distribution of the last status seen of the sampled ibtps, and
+// the latency from sending an ibtp until its final status is seen. The final status is
+// stamped when a poll sees it, so FinalLatency overstates the latency by up to Resolution
+type StatusStats struct {
+	Sample        int           `json:"sample"` // one ibtp in every Sample is tracked
+	Tracked       int64         `json:"tracked"`
+	Unknown       int64         `json:"unknown"` // never found on the relay chain
+	Begin         int64         `json:"begin"`
+	BeginFailure  int64         `json:"begin_failure"`
+	BeginRollback int64         `json:"begin_rollback"`
+	Success       int64         `json:"success"`
+	Failure       int64         `json:"failure"`
+	Rollback      int64         `json:"rollback"`
+	QueryErrors   int64         `json:"query_errors"`
+	FinalLatency  StageLatency  `json:"final_latency"`
+	Resolution    time.Duration `json:"resolution"` // the poll interval bounding the error of FinalLatency
+}
+
+type statusEntry struct {
+	id     string
+	sent   int64
+	found  bool
+	status pb.TransactionStatus
+	final  bool
+}
+
+// statusTracker samples the ibtps sent by the bees and polls them until their final status
+type statusTracker struct {
+	client rpcx.Client
+	every  uint64
+	count  uint64
+
+	lock    sync.Mutex
+	entries []*statusEntry
+	errors  int64
+
+	final *reservoir.Latencies
+}
+
+func newStatusTracker(client rpcx.Client, every int) *statusTracker {
+	return &statusTracker{
+		client: client,
+		every:  uint64(every),
+		final:  reservoir.New(),
+	}
+}
+
+// sent samples the ibtps of txs accepted by a node
+func (t *statusTracker) sent(txs []*pb.BxhTransaction) {
+	for _, tx := range txs {
+		if tx.IBTP == nil || (atomic.AddUint64(&t.count, 1)-1)%t.every != 0 {
+			continue
+		}
+		t.lock.Lock()
+		if len(t.entries) < MaxStatusTracked {
+			t.entries = append(t.entries, &statusEntry{id: tx.IBTP.ID(), sent: tx.Timestamp})
+		}
+		t.lock.Unlock()
+	}
+}
+
+// run polls the status of the sampled ibtps until ctx is done
+func (t *statusTracker) run(ctx context.Context) {
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.poll()
+		}
+	}
+}
+
+// poll queries the status of every sampled ibtp not final yet
+func (t *statusTracker) poll() {
+	t.lock.Lock()
+	entries := make([]*statusEntry, 0, len(t.entries))
+	for _, entry := range t.entries {
+		if !entry.final {
+			entries = append(entries, entry)
+		}
+	}
+	t.lock.Unlock()
+
+	ch := make(chan *statusEntry)
+	var wg sync.WaitGroup
+	wg.Add(statusPollWorkers)
+	for i := 0; i < statusPollWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for entry := range ch {
+				status, err := ibtpStatus(t.client, entry.id)
+				if err != nil {
+					// an ibtp not committed yet has no status
+					t.lock.Lock()
+					if entry.found {
+						t.errors++
+					}
+					t.lock.Unlock()
+					continue
+				}
+				t.update(entry, status, time.Now().UnixNano())
+			}
+		}()
+	}
+	for _, entry := range entries {
+		ch <- entry
+	}
+	close(ch)
+	wg.Wait()
+}
+
+func (t *statusTracker) update(entry *statusEntry, status pb.TransactionStatus, now int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	entry.found = true
+	entry.status = status
+	if isFinalStatus(status) {
+		entry.final = true
+		t.final.Add(time.Duration(now - entry.sent))
+	}
+}
+
+// isFinalStatus reports whether an ibtp in status changes no more
+func isFinalStatus(status pb.TransactionStatus) bool {
+	switch status {
+	case pb.TransactionStatus_BEGIN_FAILURE, pb.TransactionStatus_SUCCESS,
+		pb.TransactionStatus_FAILURE, pb.TransactionStatus_ROLLBACK:
+		return true
+	}
+	return false
+}
+
+func (t *statusTracker) summary() *StatusStats {
+	t.lock.Lock()
+	stats := &StatusStats{
+		Sample:      int(t.every),
+		Tracked:     int64(len(t.entries)),
+		QueryErrors: t.errors,
+		Resolution:  statusPollInterval,
+	}
+	for _, entry := range t.entries {
+		if !entry.found {
+			stats.Unknown++
+			continue
+		}
+		switch entry.status {
+		case pb.TransactionStatus_BEGIN:
+			stats.Begin++
+		case pb.TransactionStatus_BEGIN_FAILURE:
+			stats.BeginFailure++
+		case pb.TransactionStatus_BEGIN_ROLLBACK:
+			stats.BeginRollback++
+		case pb.TransactionStatus_SUCCESS:
+			stats.Success++
+		case pb.TransactionStatus_FAILURE:
+			stats.Failure++
+		case pb.TransactionStatus_ROLLBACK:
+			stats.Rollback++
+		}
+	}
+	t.lock.Unlock()
+
+	stats.FinalLatency = stageLatency(t.final)
+	return stats
+}
+
+// ibtpStatus queries the transaction status of the ibtp without sending a tx
+func ibtpStatus(client rpcx.Client, id string) (pb.TransactionStatus, error) {
+	tx, err := client.GenerateContractTx(pb.TransactionData_BVM, constant.TransactionMgrContractAddr.Address(), "GetStatus", rpcx.String(id))
+	if err != nil {
+		return 0, err
+	}
+	res, err := client.SendView(tx)
+	if err != nil {
+		return 0, err
+	}
+	if res.Status != pb.Receipt_SUCCESS {
+		return 0, fmt.Errorf("get status of ibtp %s: %s", id, string(res.Ret))
+	}
+	status, err := strconv.ParseInt(string(res.Ret), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pb.TransactionStatus(status), nil
+}
@@ -0,0 +1,119 @@
+package bitxhub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+)
+
+const (
+	minResubscribeBackoff = 1 * time.Second
+	maxResubscribeBackoff = 30 * time.Second
+	backfillBatch         = 64
+)
+
+// blockData is a block from the subscription, backfilled blocks are fetched after a gap
+// so their local receive time says nothing about tx latency
+type blockData struct {
+	block      *pb.Block
+	backfilled bool
+}
+
+// subscribeBlock delivers every block after the current height in height order, it resubscribes
+// with backoff when the subscription is closed and backfills the missed heights with GetBlocks
+func (b *Broker) subscribeBlock(ctx context.Context) (<-chan *blockData, error) {
+	// the start height is known before the first block so a drop before it still backfills
+	meta, err := b.client.GetChainMeta()
+	if err != nil {
+		return nil, err
+	}
+	ch, err := b.client.Subscribe(ctx, pb.SubscriptionRequest_BLOCK, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *blockData, 16)
+	go func() {
+		defer close(out)
+		last := meta.Height
+		for {
+			for data := range ch {
+				block := data.(*pb.Block)
+				height := block.BlockHeader.Number
+				if last != 0 && height <= last {
+					continue
+				}
+				if last != 0 && height > last+1 {
+					if err := b.backfill(ctx, out, last+1, height-1); err != nil {
+						log.WithField("error", err).Error("backfill block")
+						b.events.add(EventBackfillFailed, fmt.Sprintf("heights %d-%d: %s", last+1, height-1, err))
+					}
+				}
+				select {
+				case out <- &blockData{block: block}:
+				case <-ctx.Done():
+					return
+				}
+				last = height
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Warn("block subscription channel is closed")
+			b.events.add(EventDisconnect, fmt.Sprintf("block subscription closed after height %d", last))
+			ch = b.resubscribe(ctx)
+			if ch == nil {
+				return
+			}
+			b.events.add(EventReconnect, fmt.Sprintf("block subscription resumed after height %d", last))
+		}
+	}()
+	return out, nil
+}
+
+// resubscribe subscribes blocks again with exponential backoff until ctx is done
+func (b *Broker) resubscribe(ctx context.Context) <-chan interface{} {
+	backoff := minResubscribeBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		ch, err := b.client.Subscribe(ctx, pb.SubscriptionRequest_BLOCK, nil)
+		if err == nil {
+			return ch
+		}
+		log.WithField("error", err).Warnf("resubscribe block, retry in %s", backoff)
+		backoff *= 2
+		if backoff > maxResubscribeBackoff {
+			backoff = maxResubscribeBackoff
+		}
+	}
+}
+
+// backfill fetches the blocks in [begin, end] and delivers them in height order
+func (b *Broker) backfill(ctx context.Context, out chan<- *blockData, begin, end uint64) error {
+	log.Infof("backfill blocks from %d to %d", begin, end)
+	b.events.add(EventBackfill, fmt.Sprintf("heights %d-%d", begin, end))
+	for start := begin; start <= end; start += backfillBatch {
+		stop := start + backfillBatch - 1
+		if stop > end {
+			stop = end
+		}
+		res, err := b.client.GetBlocks(start, stop, true)
+		if err != nil {
+			return err
+		}
+		for _, block := range res.Blocks {
+			select {
+			case out <- &blockData{block: block, backfilled: true}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package bitxhub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/stretchr/testify/require"
+)
+
+// dropClient closes its first block subscription before any block and serves the
+// next ones from blocks
+type dropClient struct {
+	rpcx.Client
+	height     uint64
+	blocks     []uint64
+	subscribed int
+}
+
+func (c *dropClient) GetChainMeta() (*pb.ChainMeta, error) {
+	return &pb.ChainMeta{Height: c.height}, nil
+}
+
+func (c *dropClient) Subscribe(ctx context.Context, typ pb.SubscriptionRequest_Type, extra []byte) (<-chan interface{}, error) {
+	ch := make(chan interface{}, len(c.blocks))
+	c.subscribed++
+	if c.subscribed == 1 {
+		close(ch)
+		return ch, nil
+	}
+	for _, height := range c.blocks {
+		ch <- testBlock(height)
+	}
+	return ch, nil
+}
+
+func (c *dropClient) GetBlocks(start uint64, end uint64, fullTx bool) (*pb.GetBlocksResponse, error) {
+	res := &pb.GetBlocksResponse{}
+	for height := start; height <= end; height++ {
+		res.Blocks = append(res.Blocks, testBlock(height))
+	}
+	return res, nil
+}
+
+func testBlock(height uint64) *pb.Block {
+	return &pb.Block{BlockHeader: &pb.BlockHeader{Number: height}}
+}
+
+// TestSubscribeBlockDropBeforeFirstBlock backfills from the start height when the
+// subscription drops before its first block
+func TestSubscribeBlockDropBeforeFirstBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &Broker{
+		client: &dropClient{height: 10, blocks: []uint64{10, 13}},
+		events: newEventRecorder(),
+	}
+	ch, err := b.subscribeBlock(ctx)
+	require.NoError(t, err)
+
+	want := []struct {
+		height     uint64
+		backfilled bool
+	}{
+		{11, true},
+		{12, true},
+		{13, false},
+	}
+	for _, w := range want {
+		data := <-ch
+		require.Equal(t, w.height, data.block.BlockHeader.Number)
+		require.Equal(t, w.backfilled, data.backfilled)
+	}
+}
@@ -0,0 +1,208 @@
+package bitxhub
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/tps"
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// SweepResult is the outcome of one combination of a parameter sweep
+type SweepResult struct {
+	Concurrent  int
+	TPS         int
+	Type        string
+	PayloadSize int
+	Nodes       int
+	Crypto      string
+	Report      *Report
+	Err         error
+}
+
+// Label returns a short description of the combination
+func (r *SweepResult) Label() string {
+	return fmt.Sprintf("c=%d tps=%d type=%s size=%d nodes=%d crypto=%s",
+		r.Concurrent, r.TPS, r.Type, r.PayloadSize, r.Nodes, r.Crypto)
+}
+
+func (r *SweepResult) record() []string {
+	record := []string{
+		strconv.Itoa(r.Concurrent),
+		strconv.Itoa(r.TPS),
+		r.Type,
+		strconv.Itoa(r.PayloadSize),
+		strconv.Itoa(r.Nodes),
+		r.Crypto,
+	}
+	if r.Report == nil {
+		msg := "interrupted"
+		if r.Err != nil {
+			msg = r.Err.Error()
+		}
+		record = append(record, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", msg)
+		return record
+	}
+	ms := func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 2, 64)
+	}
+	return append(record,
+		strconv.FormatFloat(r.Report.TPS, 'f', 2, 64),
+		strconv.FormatFloat(r.Report.TPSStdDev, 'f', 2, 64),
+		strconv.FormatInt(r.Report.Sent, 10),
+		strconv.FormatInt(r.Report.Failed, 10),
+		strconv.FormatInt(r.Report.Committed, 10),
+		strconv.FormatInt(r.Report.Missing, 10),
+		strconv.FormatFloat(r.Report.FailureRatio(), 'f', 4, 64),
+		ms(r.Report.AvgLatency),
+		ms(r.Report.P50Latency),
+		ms(r.Report.P90Latency),
+		ms(r.Report.P99Latency),
+		ms(r.Report.MaxLatency),
+		ms(r.Report.Block.AvgInterval),
+		strconv.FormatFloat(r.Report.Block.AvgTxs, 'f', 2, 64),
+		strconv.FormatFloat(r.Report.Block.EmptyRatio(), 'f', 4, 64),
+		"",
+	)
+}
+
+var comparisonHeader = []string{
+	"concurrent", "tps", "type", "payload_size", "nodes", "crypto",
+	"achieved_tps", "tps_stddev", "sent", "failed", "committed", "missing", "failure_ratio",
+	"avg_latency_ms", "p50_latency_ms", "p90_latency_ms", "p99_latency_ms", "max_latency_ms",
+	"avg_block_interval_ms", "avg_txs_per_block", "empty_block_ratio", "error",
+}
+
+var comparisonTemplate = template.Must(template.New("comparison").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>premo sweep</title></head>
+<body>
+<h1>premo sweep</h1>
+<table border="1" cellspacing="0" cellpadding="4">
+<tr><th>#</th>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range $i, $row := .Rows}}<tr><td>{{$i}}</td>{{range $row}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{range .Charts}}<div>{{.}}</div>
+{{end}}</body>
+</html>
+`))
+
+// WriteComparison writes the results of a sweep as comparison.csv and comparison.html into dir
+func WriteComparison(dir string, results []*SweepResult) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, result.record())
+	}
+
+	f, err := os.Create(filepath.Join(dir, "comparison.csv"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.Write(comparisonHeader); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+
+	var charts []template.HTML
+	tps := make([]float64, len(results))
+	p99 := make([]float64, len(results))
+	for i, result := range results {
+		if result.Report != nil {
+			tps[i] = result.Report.TPS
+			p99[i] = float64(result.Report.P99Latency) / float64(time.Millisecond)
+		}
+	}
+	for _, c := range []struct {
+		title  string
+		values []float64
+	}{{"Achieved TPS", tps}, {"P99 latency (ms)", p99}} {
+		svg, err := barChart(c.title, c.values)
+		if err != nil {
+			log.Warnf("render %s chart: %s", c.title, err)
+			continue
+		}
+		charts = append(charts, template.HTML(svg))
+	}
+
+	h, err := os.Create(filepath.Join(dir, "comparison.html"))
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	return comparisonTemplate.Execute(h, map[string]interface{}{
+		"Header": comparisonHeader,
+		"Rows":   rows,
+		"Charts": charts,
+	})
+}
+
+func barChart(title string, values []float64) (string, error) {
+	max := 1.0
+	bars := make([]chart.Value, 0, len(values))
+	for i, v := range values {
+		if v > max {
+			max = v
+		}
+		bars = append(bars, chart.Value{Label: strconv.Itoa(i), Value: v})
+	}
+	width := 60 * len(bars)
+	if width < 1024 {
+		width = 1024
+	}
+	graph := chart.BarChart{
+		Title:    title,
+		Width:    width,
+		Height:   512,
+		BarWidth: 40,
+		YAxis: chart.YAxis{
+			Range: &chart.ContinuousRange{Min: 0, Max: max * 1.1},
+		},
+		Bars: bars,
+	}
+	buf := &bytes.Buffer{}
+	if err := graph.Render(chart.SVG, buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WaitChainDrain waits until the chain height stays the same for the quiet period of config
+func WaitChainDrain(addr string, config *tps.Config) error {
+	pk, _, err := repo.KeyPriv()
+	if err != nil {
+		return err
+	}
+	client, err := rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: addr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Stop()
+	}()
+
+	height, err := tps.Drain(client, config, nil)
+	if err != nil {
+		return err
+	}
+	log.Infof("chain is drained at height %d", height)
+	return nil
+}
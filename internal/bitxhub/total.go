@@ -0,0 +1,105 @@
+package bitxhub
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/meshplus/bitxhub-model/pb"
+)
+
+// TotalStats is the outcome of an exact-count run, every generated tx is expected in a block
+type TotalStats struct {
+	Total     int      `json:"total"`
+	Generated int      `json:"generated"`
+	Committed int      `json:"committed"`
+	Dropped   int      `json:"dropped"` // given up by the retry policy, generated again in their place
+	Missing   []string `json:"missing"` // hashes of the generated txs not seen in a block
+}
+
+// totalTracker follows the txs of an exact-count run from generation to commit
+type totalTracker struct {
+	total int
+
+	lock      sync.Mutex
+	generated int
+	committed int
+	dropped   int
+	pending   map[string]struct{}
+	done      chan struct{} // closed once every tx is generated and committed
+	closed    bool
+}
+
+func newTotalTracker(total int) *totalTracker {
+	return &totalTracker{
+		total:   total,
+		pending: make(map[string]struct{}, total),
+		done:    make(chan struct{}),
+	}
+}
+
+// quotas splits the total over n bees
+func (t *totalTracker) quotas(n int) []uint64 {
+	quotas := make([]uint64, n)
+	for i := range quotas {
+		quotas[i] = uint64(t.total / n)
+		if i < t.total%n {
+			quotas[i]++
+		}
+	}
+	return quotas
+}
+
+// add is called when a bee generates tx, before it is sent
+func (t *totalTracker) add(tx *pb.BxhTransaction) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.generated++
+	t.pending[tx.GetHash().String()] = struct{}{}
+}
+
+// commit is called when tx is seen in a block
+func (t *totalTracker) commit(tx *pb.BxhTransaction) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	hash := tx.GetHash().String()
+	if _, ok := t.pending[hash]; !ok {
+		return
+	}
+	delete(t.pending, hash)
+	t.committed++
+	if !t.closed && t.generated == t.total && len(t.pending) == 0 {
+		t.closed = true
+		close(t.done)
+	}
+}
+
+// drop is called when a bee gives tx up, it no longer counts as generated so the bee
+// generates another in its place
+func (t *totalTracker) drop(tx *pb.BxhTransaction) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	hash := tx.GetHash().String()
+	if _, ok := t.pending[hash]; !ok {
+		return
+	}
+	delete(t.pending, hash)
+	t.generated--
+	t.dropped++
+}
+
+func (t *totalTracker) summary() *TotalStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	stats := &TotalStats{
+		Total:     t.total,
+		Generated: t.generated,
+		Committed: t.committed,
+		Dropped:   t.dropped,
+		Missing:   make([]string, 0, len(t.pending)),
+	}
+	for hash := range t.pending {
+		stats.Missing = append(stats.Missing, hash)
+	}
+	sort.Strings(stats.Missing)
+	return stats
+}
@@ -0,0 +1,33 @@
+package bitxhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalTrackerQuotas(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		bees  int
+		want  []uint64
+	}{
+		{"even", 8, 4, []uint64{2, 2, 2, 2}},
+		{"remainder to the first bees", 10, 4, []uint64{3, 3, 2, 2}},
+		{"fewer txs than bees", 2, 3, []uint64{1, 1, 0}},
+		{"one bee", 7, 1, []uint64{7}},
+		{"no txs", 0, 2, []uint64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotas := newTotalTracker(tt.total).quotas(tt.bees)
+			require.Equal(t, tt.want, quotas)
+			var sum uint64
+			for _, q := range quotas {
+				sum += q
+			}
+			require.Equal(t, uint64(tt.total), sum)
+		})
+	}
+}
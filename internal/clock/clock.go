@@ -0,0 +1,132 @@
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+)
+
+// Offset is the estimated offset of a node clock, the local time minus the node time.
+// The offset absorbs the fastest block delivery delay, Error is the spread of the
+// fastest deliveries and bounds how stable the estimate is.
+//
+// The estimate is biased: a block timestamp is the clock of the leader when it proposed the
+// block, so the offset also holds the fastest consensus and delivery delay of a block, and
+// the leader clock rather than the one of the node. Latencies corrected by it are understated
+// by that delay.
+type Offset struct {
+	Node    string        `json:"node"`
+	Offset  time.Duration `json:"offset"`
+	Error   time.Duration `json:"error"`
+	Samples int           `json:"samples"`
+}
+
+type node struct {
+	first   time.Time
+	samples []int64
+	ready   bool
+	offset  Offset
+}
+
+// Estimator estimates the clock offset to every node by comparing block timestamps
+// against local receive times over a warm-up window
+type Estimator struct {
+	warmup time.Duration
+	lock   sync.RWMutex
+	nodes  map[string]*node
+}
+
+// NewEstimator returns an estimator, every node is ready after warmup
+func NewEstimator(warmup time.Duration) *Estimator {
+	return &Estimator{
+		warmup: warmup,
+		nodes:  make(map[string]*node),
+	}
+}
+
+// Observe records a block of node whose timestamp is blockTimestamp received locally at now,
+// the sample is the skew plus the delivery delay of the block, which no sample can tell apart
+func (e *Estimator) Observe(addr string, blockTimestamp, now int64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	n, ok := e.nodes[addr]
+	if !ok {
+		n = &node{first: time.Now()}
+		e.nodes[addr] = n
+	}
+	if n.ready {
+		return
+	}
+	n.samples = append(n.samples, now-blockTimestamp)
+	if time.Since(n.first) < e.warmup {
+		return
+	}
+
+	sort.Slice(n.samples, func(i, j int) bool { return n.samples[i] < n.samples[j] })
+	n.offset = Offset{
+		Node:    addr,
+		Offset:  time.Duration(n.samples[0]),
+		Error:   time.Duration(n.samples[len(n.samples)/10] - n.samples[0]),
+		Samples: len(n.samples),
+	}
+	n.ready = true
+	n.samples = nil
+}
+
+// Offset returns the offset of node and whether its warm-up is over
+func (e *Estimator) Offset(addr string) (time.Duration, bool) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	n, ok := e.nodes[addr]
+	if !ok || !n.ready {
+		return 0, false
+	}
+	return n.offset.Offset, true
+}
+
+// Offsets returns the offsets of all nodes whose warm-up is over
+func (e *Estimator) Offsets() []*Offset {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	ret := make([]*Offset, 0, len(e.nodes))
+	for _, n := range e.nodes {
+		if n.ready {
+			offset := n.offset
+			ret = append(ret, &offset)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Node < ret[j].Node })
+	return ret
+}
+
+// Run subscribes blocks of the node until its warm-up is over or ctx is done
+func (e *Estimator) Run(ctx context.Context, client rpcx.Client, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch, err := client.Subscribe(ctx, pb.SubscriptionRequest_BLOCK, nil)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case data, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			block := data.(*pb.Block)
+			e.Observe(addr, block.BlockHeader.Timestamp, time.Now().UnixNano())
+			if _, ready := e.Offset(addr); ready {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-kit/crypto/asym"
+	"github.com/meshplus/bitxhub-kit/fileutil"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/appchain"
+	"github.com/meshplus/premo/internal/bitxhub"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/sirupsen/logrus"
+)
+
+// Status is the outcome of a check
+type Status string
+
+// outcomes of a check
+const (
+	Pass Status = "PASS"
+	Warn Status = "WARN"
+	Fail Status = "FAIL"
+	Skip Status = "SKIP"
+)
+
+// Modules are the governance modules whose strategies the admins must be able to satisfy
+var Modules = []string{"appchain_mgr", "service_mgr", "rule_mgr", "node_mgr", "role_mgr", "dapp_mgr"}
+
+// MaxHeightLag is the height gap between nodes above which they are reported as lagging
+const MaxHeightLag = 10
+
+var log = logrus.New()
+
+// Config is what a benchmark would run with
+type Config struct {
+	KeyPath        string
+	BitxhubAddr    []string
+	Concurrent     int
+	Type           string
+	MultiDestChain bool
+	Appchain       string // name of the appchain profile
+}
+
+// Result is the outcome of one check with the way to fix it
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+type doctor struct {
+	config  *Config
+	results []*Result
+
+	key     crypto.PrivateKey
+	from    *types.Address
+	admins  *admin.Set
+	clients map[string]rpcx.Client
+	heights map[string]uint64
+}
+
+// Run checks the environment a benchmark of config needs, later checks are skipped when
+// what they rely on failed
+func Run(config *Config) []*Result {
+	d := &doctor{
+		config:  config,
+		clients: make(map[string]rpcx.Client),
+		heights: make(map[string]uint64),
+	}
+	defer func() {
+		for _, client := range d.clients {
+			_ = client.Stop()
+		}
+	}()
+
+	d.checkRepo()
+	d.checkKeys()
+	for _, addr := range config.BitxhubAddr {
+		d.checkNode(addr)
+	}
+	client := d.client()
+	d.checkNonce()
+	d.checkProgress(client)
+	d.checkBalance(client)
+	d.checkStrategies(client)
+	return d.results
+}
+
+// Failed reports whether any check failed
+func Failed(results []*Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the results as a table followed by the fixes of the checks not passed
+func Print(w io.Writer, results []*Result) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, r.Status, r.Detail)
+	}
+	_ = tw.Flush()
+	for _, r := range results {
+		if r.Fix != "" && (r.Status == Fail || r.Status == Warn) {
+			fmt.Fprintf(w, "\n%s: %s", r.Name, r.Fix)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func (d *doctor) add(name string, status Status, detail, fix string) {
+	d.results = append(d.results, &Result{Name: name, Status: status, Detail: detail, Fix: fix})
+}
+
+func (d *doctor) skip(name, reason string) {
+	d.add(name, Skip, reason, "")
+}
+
+// client returns the client of the first reachable node
+func (d *doctor) client() rpcx.Client {
+	for _, addr := range d.config.BitxhubAddr {
+		if client, ok := d.clients[addr]; ok {
+			return client
+		}
+	}
+	return nil
+}
+
+func (d *doctor) checkRepo() {
+	const name = "repo layout"
+	root, err := repo.PathRootWithDefault()
+	if err != nil {
+		d.add(name, Fail, err.Error(), "run `premo init`")
+		return
+	}
+	p, err := appchain.Lookup(d.config.Appchain)
+	if err != nil {
+		d.add(name, Fail, err.Error(), "fix --appchain or the profiles in appchains.json")
+		return
+	}
+	var missing []string
+	for _, file := range []string{p.RuleWasm, p.TrustRoot, p.ProofFile} {
+		if file == "" {
+			continue
+		}
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, file)
+		}
+		if !fileutil.Exist(path) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) != 0 {
+		d.add(name, Fail, "missing "+strings.Join(missing, ", "),
+			"run `premo init` again, or fix the files of the appchain profile in appchains.json")
+		return
+	}
+	d.add(name, Pass, root, "")
+}
+
+func (d *doctor) checkKeys() {
+	const name = "key files"
+	fix := fmt.Sprintf("the key files must be encrypted with password %q, regenerate them or fix --key_path and admins.json", repo.KeyPassword)
+	key, err := asym.RestorePrivateKey(d.config.KeyPath, repo.KeyPassword)
+	if err != nil {
+		d.add(name, Fail, fmt.Sprintf("%s: %s", d.config.KeyPath, err), fix)
+		return
+	}
+	from, err := key.PublicKey().Address()
+	if err != nil {
+		d.add(name, Fail, err.Error(), fix)
+		return
+	}
+	admins, err := admin.Load()
+	if err != nil {
+		d.add(name, Fail, err.Error(), fix)
+		return
+	}
+	d.key, d.from, d.admins = key, from, admins
+	d.add(name, Pass, fmt.Sprintf("admin %s and %d voters decrypt", from.String(), len(admins.Admins)), "")
+}
+
+func (d *doctor) checkNode(addr string) {
+	name := "node " + addr
+	fix := "start the node or fix --remote_bitxhub_addr"
+	key := d.key
+	if key == nil {
+		var err error
+		if key, _, err = repo.KeyPriv(); err != nil {
+			d.add(name, Fail, err.Error(), "")
+			return
+		}
+	}
+	client, err := rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: addr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(key),
+	)
+	if err != nil {
+		d.add(name, Fail, err.Error(), fix)
+		return
+	}
+	meta, err := client.GetChainMeta()
+	if err != nil {
+		_ = client.Stop()
+		d.add(name, Fail, err.Error(), fix)
+		return
+	}
+	d.clients[addr] = client
+	d.heights[addr] = meta.Height
+	d.add(name, Pass, fmt.Sprintf("height %d", meta.Height), "")
+}
+
+// checkNonce compares the pending nonce of the admin across the nodes. A node lagging
+// behind, or holding txs of a crashed run the others never received, answers another
+// nonce, and the bees taking their nonce from it stall. The pending nonce includes the
+// pooled txs, so txs stuck on every node agree and pass here, checkProgress catches them.
+func (d *doctor) checkNonce() {
+	const name = "admin nonce agreement"
+	if d.from == nil || len(d.clients) == 0 {
+		d.skip(name, "needs the admin key and a reachable node")
+		return
+	}
+	nonces := make(map[uint64][]string)
+	for _, addr := range d.config.BitxhubAddr {
+		client, ok := d.clients[addr]
+		if !ok {
+			continue
+		}
+		nonce, err := client.GetPendingNonceByAccount(d.from.String())
+		if err != nil {
+			d.add(name, Fail, fmt.Sprintf("%s: %s", addr, err), "")
+			return
+		}
+		nonces[nonce] = append(nonces[nonce], addr)
+	}
+	if len(nonces) > 1 {
+		var detail []string
+		for nonce, addrs := range nonces {
+			detail = append(detail, fmt.Sprintf("%d on %s", nonce, strings.Join(addrs, ",")))
+		}
+		d.add(name, Fail, "pending nonces differ across nodes: "+strings.Join(detail, "; "),
+			"a node lags behind or pools txs the others lack, wait for the nodes to sync or restart the odd one")
+		return
+	}
+	for nonce := range nonces {
+		d.add(name, Pass, fmt.Sprintf("pending nonce %d on every node", nonce), "")
+	}
+}
+
+// checkProgress sends a zero transfer from the admin to itself and waits for it to be
+// packed, bitxhub only makes blocks when there are txs
+func (d *doctor) checkProgress(client rpcx.Client) {
+	const name = "chain progress"
+	if d.from == nil || client == nil {
+		d.skip(name, "needs the admin key and a reachable node")
+		return
+	}
+	var lowest, highest uint64
+	first := true
+	for _, height := range d.heights {
+		if first || height < lowest {
+			lowest = height
+		}
+		if first || height > highest {
+			highest = height
+		}
+		first = false
+	}
+
+	before, err := client.GetChainMeta()
+	if err != nil {
+		d.add(name, Fail, err.Error(), "")
+		return
+	}
+	data := &pb.TransactionData{Amount: "0"}
+	payload, err := data.Marshal()
+	if err != nil {
+		d.add(name, Fail, err.Error(), "")
+		return
+	}
+	nonce, err := client.GetPendingNonceByAccount(d.from.String())
+	if err != nil {
+		d.add(name, Fail, err.Error(), "")
+		return
+	}
+	start := time.Now()
+	receipt, err := client.SendTransactionWithReceipt(&pb.BxhTransaction{
+		From:      d.from,
+		To:        d.from,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	}, &rpcx.TransactOpts{From: d.from.String(), Nonce: nonce, PrivKey: d.key})
+	if err != nil {
+		d.add(name, Fail, "probe tx not packed: "+err.Error(),
+			"check the consensus of the nodes, at least 2f+1 nodes must be up and connected")
+		return
+	}
+	after, err := client.GetChainMeta()
+	if err != nil {
+		d.add(name, Fail, err.Error(), "")
+		return
+	}
+	if receipt.Status != pb.Receipt_SUCCESS || after.Height <= before.Height {
+		d.add(name, Fail, fmt.Sprintf("probe tx status %s, height %d to %d", receipt.Status, before.Height, after.Height),
+			"check the logs of the nodes")
+		return
+	}
+	detail := fmt.Sprintf("probe tx packed in %s, height %d", time.Since(start).Round(time.Millisecond), after.Height)
+	if highest-lowest > MaxHeightLag {
+		d.add(name, Warn, fmt.Sprintf("%s, nodes lag %d blocks", detail, highest-lowest),
+			"a lagging node is still syncing or partitioned, do not send load to it")
+		return
+	}
+	d.add(name, Pass, detail, "")
+}
+
+// checkBalance compares the balances of the admin and the voters with what the setup of
+// the bees costs them
+func (d *doctor) checkBalance(client rpcx.Client) {
+	const name = "setup funding"
+	if d.from == nil || client == nil {
+		d.skip(name, "needs the admin key and a reachable node")
+		return
+	}
+	funding := bitxhub.Estimate(&bitxhub.Config{
+		Concurrent:     d.config.Concurrent,
+		Type:           d.config.Type,
+		MultiDestChain: d.config.MultiDestChain,
+	})
+	short, err := bitxhub.CheckFunds(client, d.from, d.admins, funding)
+	if err != nil {
+		d.add(name, Fail, err.Error(), "")
+		return
+	}
+	if len(short) != 0 {
+		d.add(name, Fail, strings.Join(short, "; "),
+			"transfer to the accounts, use another --key_path or lower --concurrent")
+		return
+	}
+	d.add(name, Pass, fmt.Sprintf("%d bees need %s from the admin and %s from every voter",
+		d.config.Concurrent, funding.Admin.String(), funding.Voter.String()), "")
+}
+
+// checkStrategies checks the admins of admins.json can approve proposals of every module,
+// they are taken as all the governance admins of the chain
+func (d *doctor) checkStrategies(client rpcx.Client) {
+	const name = "governance strategy"
+	if d.admins == nil || client == nil {
+		d.skip(name, "needs the admin set and a reachable node")
+		return
+	}
+	total := d.admins.TotalWeight()
+	var failed, unknown, passed []string
+	for _, module := range Modules {
+		strategy, err := admin.GetStrategy(client, module)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", module, err))
+			continue
+		}
+		quorum, ok := strategy.Quorum(total)
+		switch {
+		case !ok:
+			unknown = append(unknown, fmt.Sprintf("%s %s %q", module, strategy.Typ, strategy.Extra))
+		case quorum > total:
+			failed = append(failed, fmt.Sprintf("%s needs %d of weight %d", module, quorum, total))
+		default:
+			passed = append(passed, module)
+		}
+	}
+	switch {
+	case len(failed) != 0:
+		d.add(name, Fail, strings.Join(failed, "; "),
+			"add the missing admins to admins.json, or relax the strategy of the module")
+	case len(unknown) != 0:
+		d.add(name, Warn, "quorum not computed for "+strings.Join(unknown, "; "),
+			"votes are cast until the proposal settles, make sure admins.json lists enough admins")
+	default:
+		d.add(name, Pass, fmt.Sprintf("weight %d approves %s", total, strings.Join(passed, ", ")), "")
+	}
+}
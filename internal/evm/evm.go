@@ -13,12 +13,12 @@ import (
 	rpcx "github.com/meshplus/go-bitxhub-client"
 	eth "github.com/meshplus/go-eth-client"
 	"github.com/meshplus/go-eth-client/utils"
+	"github.com/meshplus/premo/internal/clock"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/tps"
 	"github.com/sirupsen/logrus"
 )
 
-const MaxBlockSize = 2048
-
 var log = logrus.New()
 var lock = sync.Mutex{}
 var maxDelay int64
@@ -45,6 +45,8 @@ type Config struct {
 	KeyPath      string
 	JsonRpc      string
 	Grpc         string
+	ClockWarmup  int        // s uint, 0 disables clock offset correction
+	Collect      tps.Config // drain wait, warm-up and cool-down of the tps collection
 	Ctx          context.Context
 	CancelFunc   context.CancelFunc
 }
@@ -53,6 +55,7 @@ type Evm struct {
 	config *Config
 	bees   []*Bee
 	client *rpcx.ChainClient
+	clock  *clock.Estimator
 }
 
 func New(config *Config) (*Evm, error) {
@@ -78,6 +81,10 @@ func New(config *Config) (*Evm, error) {
 		return nil, err
 	}
 	evm.client = client
+	if config.ClockWarmup > 0 {
+		evm.clock = clock.NewEstimator(time.Duration(config.ClockWarmup) * time.Second)
+		log.Warnf("latencies are not measured in the first %ds while the clock offset to the nodes is estimated", config.ClockWarmup)
+	}
 
 	evm.bees = make([]*Bee, 0, config.Concurrent)
 	var wg sync.WaitGroup
@@ -216,6 +223,17 @@ func (evm *Evm) Start() error {
 
 	// listen from bitxhub block
 	go evm.listenBlock()
+	if evm.clock != nil {
+		go func() {
+			if err := evm.clock.Run(evm.config.Ctx, evm.client, evm.config.Grpc); err != nil {
+				log.WithField("error", err).Error("estimate clock")
+				return
+			}
+			if offset, ok := evm.clock.Offset(evm.config.Grpc); ok {
+				log.Infof("the clock offset to node %s is %s", evm.config.Grpc, offset)
+			}
+		}()
+	}
 
 	ticker := time.NewTicker(time.Second * time.Duration(evm.config.Duration))
 	select {
@@ -284,11 +302,20 @@ func (evm *Evm) listenBlock() {
 			}
 			block := data.(*pb.Block)
 			now := time.Now().UnixNano()
+			var offset time.Duration
+			estimated := true
+			if evm.clock != nil {
+				offset, estimated = evm.clock.Offset(evm.config.Grpc)
+			}
 			for _, tx := range block.Transactions.Transactions {
 				cnt++
 				counter++
+				if !estimated {
+					// skip the latency until the clock offset is estimated
+					continue
+				}
 
-				txDelay := now - tx.GetTimeStamp()
+				txDelay := now - tx.GetTimeStamp() - int64(offset)
 				dly += txDelay
 				delayer += txDelay
 
@@ -308,39 +335,18 @@ func (evm *Evm) calTps(meta0 *pb.ChainMeta) error {
 		return err
 	}
 	log.Info("Collecting tps info, please wait...")
-	time.Sleep(20 * time.Second)
-
-	skip := (meta1.Height - meta0.Height) / 8
-	begin := meta0.Height + skip
-	end := meta1.Height - skip
-
-	var (
-		tps      uint64
-		totalTps uint64
-		count    uint64
-		tmpBegin = begin
-	)
-
-	for tmpBegin < end {
-		if end-tmpBegin > MaxBlockSize {
-			tps, err = evm.client.GetTPS(tmpBegin, tmpBegin+MaxBlockSize)
-			if err != nil {
-				return err
-			}
-			log.Infof("the TPS from block %d to %d is %d", tmpBegin, tmpBegin+MaxBlockSize, tps)
-		} else {
-			tps, err = evm.client.GetTPS(tmpBegin, end)
-			if err != nil {
-				return err
-			}
-			log.Infof("the TPS from block %d to %d is %d", tmpBegin, end, tps)
-		}
-		totalTps += tps
-		count++
-		tmpBegin = tmpBegin + MaxBlockSize
+	end := meta1.Height
+	height, err := tps.Drain(evm.client, &evm.config.Collect, nil)
+	if err != nil {
+		log.WithField("error", err).Warn("drain the sent txs")
+	}
+	if height > end {
+		end = height
 	}
 
-	log.Infof("the total TPS from block %d to %d is %d", begin, end, totalTps/count)
+	if _, err := tps.Measure(evm.client, meta0.Height, end, &evm.config.Collect); err != nil {
+		return err
+	}
 	err = evm.client.Stop()
 	if err != nil {
 		return err
@@ -0,0 +1,452 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	bxhgov "github.com/meshplus/bitxhub-core/governance"
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/meshplus/premo/internal/repo"
+)
+
+const (
+	// StatusTimeout bounds the wait for a governed object to reach the final status of a step
+	StatusTimeout = 30 * time.Second
+
+	reason       = "premo governance"
+	serviceID    = "premo"
+	dappContract = "rule.wasm" // any contract will do, the dapp only needs an address
+)
+
+// cycle is the set of objects governed by one cycle, they are fresh in every cycle
+type cycle struct {
+	key         crypto.PrivateKey // the appchain admin, also the dapp owner
+	chainID     string
+	serviceID   string
+	nodeAccount string
+	roleID      string
+	dappID      string
+	dappAddr    string
+}
+
+// step is one proposal of the cycle, sent by the governance admin or by the owner of the object
+type step struct {
+	name   string
+	target string
+	admin  bool
+	method string
+	args   func(w *worker, c *cycle) ([]*pb.Arg, error)
+	query  string // view returning the object with its status
+	id     func(c *cycle) string
+	final  bxhgov.GovernanceStatus
+}
+
+func idArgs(id func(c *cycle) string) func(w *worker, c *cycle) ([]*pb.Arg, error) {
+	return func(w *worker, c *cycle) ([]*pb.Arg, error) {
+		return []*pb.Arg{rpcx.String(id(c)), rpcx.String(reason)}, nil
+	}
+}
+
+func chainID(c *cycle) string     { return c.chainID }
+func serviceKey(c *cycle) string  { return c.serviceID }
+func nodeAccount(c *cycle) string { return c.nodeAccount }
+func roleID(c *cycle) string      { return c.roleID }
+func dappID(c *cycle) string      { return c.dappID }
+
+// cycleSteps are all steps in the order they run, every object is left in a final status
+// before the objects it depends on
+var cycleSteps = []*step{
+	{
+		name: "appchain/register", target: Appchain, method: "RegisterAppchain",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			rule, deployed, err := w.g.config.Appchain.Rule(w.client, nil)
+			if err != nil {
+				return nil, err
+			}
+			if deployed {
+				if err := w.g.created.Record(manifest.Rule, manifest.RuleID(c.chainID, rule), c.key); err != nil {
+					log.WithField("error", err).Warnf("record rule %s", rule)
+				}
+			}
+			pubKey, err := c.key.PublicKey().Bytes()
+			if err != nil {
+				return nil, err
+			}
+			return []*pb.Arg{
+				rpcx.String(c.chainID),                     //chainID
+				rpcx.String(c.chainID),                     //chainName
+				rpcx.Bytes(pubKey),                         //pubKey
+				rpcx.String(w.g.config.Appchain.ChainType), //chainType
+				rpcx.Bytes(w.g.config.TrustRoot),           //trustRoot
+				rpcx.String(w.g.config.Appchain.Broker),    //broker
+				rpcx.String(reason),                        //desc
+				rpcx.String(rule),                          //masterRuleAddr
+				rpcx.String("https://github.com"),          //masterRuleUrl
+				rpcx.String(c.chainID),                     //adminAddrs
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetAppchain", id: chainID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "appchain/update", target: Appchain, method: "UpdateAppchain",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.chainID),
+				rpcx.String(c.chainID + "-updated"), // a new name needs a proposal
+				rpcx.String(reason),
+				rpcx.Bytes(nil),
+				rpcx.String(c.chainID),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetAppchain", id: chainID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "service/register", target: Service, method: "RegisterService",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.chainID),
+				rpcx.String(serviceID),
+				rpcx.String(c.serviceID),
+				rpcx.String("CallContract"),
+				rpcx.String("test"),
+				rpcx.Uint64(1),
+				rpcx.String(""),
+				rpcx.String("test"),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetServiceInfo", id: serviceKey, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "service/update", target: Service, method: "UpdateService",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.serviceID),
+				rpcx.String(c.serviceID + "-updated"),
+				rpcx.String("test"),
+				rpcx.String(""),
+				rpcx.String("test"),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetServiceInfo", id: serviceKey, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "service/freeze", target: Service, admin: true, method: "FreezeService", args: idArgs(serviceKey),
+		query: "GetServiceInfo", id: serviceKey, final: bxhgov.GovernanceFrozen,
+	},
+	{
+		name: "service/activate", target: Service, method: "ActivateService", args: idArgs(serviceKey),
+		query: "GetServiceInfo", id: serviceKey, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "node/register", target: Node, admin: true, method: "RegisterNode",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.nodeAccount), //nodeAccount
+				rpcx.String("nvpNode"),     //nodeType
+				rpcx.String(""),            //nodePid
+				rpcx.Uint64(0),             //nodeVpId
+				rpcx.String(c.nodeAccount), //nodeName
+				rpcx.String(c.chainID),     //permitStr
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetNode", id: nodeAccount, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "node/update", target: Node, admin: true, method: "UpdateNode",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.nodeAccount),
+				rpcx.String(c.nodeAccount + "-updated"),
+				rpcx.String(c.chainID),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetNode", id: nodeAccount, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "role/register", target: Role, admin: true, method: "RegisterRole",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.roleID),
+				rpcx.String("auditAdmin"),
+				rpcx.String(c.nodeAccount),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetRoleInfoById", id: roleID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "role/freeze", target: Role, admin: true, method: "FreezeRole", args: idArgs(roleID),
+		query: "GetRoleInfoById", id: roleID, final: bxhgov.GovernanceFrozen,
+	},
+	{
+		name: "role/activate", target: Role, admin: true, method: "ActivateRole", args: idArgs(roleID),
+		query: "GetRoleInfoById", id: roleID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "role/logout", target: Role, admin: true, method: "LogoutRole", args: idArgs(roleID),
+		query: "GetRoleInfoById", id: roleID, final: bxhgov.GovernanceForbidden,
+	},
+	{
+		name: "node/logout", target: Node, admin: true, method: "LogoutNode", args: idArgs(nodeAccount),
+		query: "GetNode", id: nodeAccount, final: bxhgov.GovernanceForbidden,
+	},
+	{
+		name: "dapp/register", target: Dapp, method: "RegisterDapp",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			addr, err := w.client.DeployContract(w.g.dappContract, nil)
+			if err != nil {
+				return nil, fmt.Errorf("deploy dapp contract: %w", err)
+			}
+			c.dappAddr = addr.String()
+			return []*pb.Arg{
+				rpcx.String(c.dappID),
+				rpcx.String("application"),
+				rpcx.String(reason),
+				rpcx.String("https://github.com"),
+				rpcx.String(c.dappAddr),
+				rpcx.String(""),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetDapp", id: dappID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "dapp/update", target: Dapp, method: "UpdateDapp",
+		args: func(w *worker, c *cycle) ([]*pb.Arg, error) {
+			return []*pb.Arg{
+				rpcx.String(c.dappID),
+				rpcx.String(c.dappID + "-updated"),
+				rpcx.String(reason),
+				rpcx.String("https://github.com"),
+				rpcx.String(c.dappAddr),
+				rpcx.String(""),
+				rpcx.String(reason),
+			}, nil
+		},
+		query: "GetDapp", id: dappID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "dapp/freeze", target: Dapp, admin: true, method: "FreezeDapp", args: idArgs(dappID),
+		query: "GetDapp", id: dappID, final: bxhgov.GovernanceFrozen,
+	},
+	{
+		name: "dapp/activate", target: Dapp, method: "ActivateDapp", args: idArgs(dappID),
+		query: "GetDapp", id: dappID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "service/logout", target: Service, method: "LogoutService", args: idArgs(serviceKey),
+		query: "GetServiceInfo", id: serviceKey, final: bxhgov.GovernanceForbidden,
+	},
+	{
+		name: "appchain/freeze", target: Appchain, admin: true, method: "FreezeAppchain", args: idArgs(chainID),
+		query: "GetAppchain", id: chainID, final: bxhgov.GovernanceFrozen,
+	},
+	{
+		name: "appchain/activate", target: Appchain, method: "ActivateAppchain", args: idArgs(chainID),
+		query: "GetAppchain", id: chainID, final: bxhgov.GovernanceAvailable,
+	},
+	{
+		name: "appchain/logout", target: Appchain, method: "LogoutAppchain", args: idArgs(chainID),
+		query: "GetAppchain", id: chainID, final: bxhgov.GovernanceForbidden,
+	},
+}
+
+// contracts are the governance contracts of the targets
+var contracts = map[string]*types.Address{
+	Appchain: constant.AppchainMgrContractAddr.Address(),
+	Service:  constant.ServiceMgrContractAddr.Address(),
+	Node:     constant.NodeManagerContractAddr.Address(),
+	Role:     constant.RoleContractAddr.Address(),
+	Dapp:     constant.DappMgrContractAddr.Address(),
+}
+
+// stepsOf returns the steps of the targets in cycle order
+func stepsOf(targets []string) []*step {
+	selected := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		selected[t] = true
+	}
+	var steps []*step
+	for _, s := range cycleSteps {
+		if selected[s.target] {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// worker runs cycles one after another with its own client
+type worker struct {
+	g      *Governance
+	client *rpcx.ChainClient
+	voter  *admin.Voter
+}
+
+func newWorker(g *Governance, addr string) (*worker, error) {
+	pk, _, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: addr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &worker{
+		g:      g,
+		client: client,
+		voter:  admin.NewVoter(client, g.admins, reason),
+	}, nil
+}
+
+func (w *worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := w.cycle(ctx)
+		// a cycle cut by the end of the run is neither done nor failed
+		if ctx.Err() != nil {
+			return
+		}
+		atomic.AddInt64(&w.g.cycles, 1)
+		if err != nil {
+			atomic.AddInt64(&w.g.failed, 1)
+			log.WithField("error", err).Debug("governance cycle failed")
+		}
+	}
+}
+
+// cycle funds a fresh appchain admin and runs every step, it stops at the first failed step
+func (w *worker) cycle(ctx context.Context) error {
+	c, err := w.newCycle()
+	if err != nil {
+		return err
+	}
+	if err := w.g.transfer(w.client, types.NewAddressByStr(c.chainID)); err != nil {
+		return err
+	}
+	w.client.SetPrivateKey(c.key)
+	for _, s := range w.g.steps {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		approve, final, proposed, err := w.step(ctx, s, c)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		w.g.record(s.name, approve, final, proposed, err)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+	}
+	return nil
+}
+
+func (w *worker) newCycle() (*cycle, error) {
+	pk, from, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	_, node, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	_, role, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	return &cycle{
+		key:         pk,
+		chainID:     from.String(),
+		serviceID:   from.String() + ":" + serviceID,
+		nodeAccount: node.String(),
+		roleID:      role.String(),
+		dappID:      from.String() + "-0", // the first dapp of the owner
+	}, nil
+}
+
+// step sends the tx of s, votes its proposal and waits for the final status. The latencies
+// are from the receipt creating the proposal, proposed is false if no proposal was needed.
+func (w *worker) step(ctx context.Context, s *step, c *cycle) (approve, final time.Duration, proposed bool, err error) {
+	args, err := s.args(w, c)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	receipt, err := w.invoke(s, args)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	created := time.Now()
+	if receipt.Status != pb.Receipt_SUCCESS {
+		return 0, 0, false, fmt.Errorf("%s", string(receipt.Ret))
+	}
+	w.remember(s, c)
+	id, err := admin.ProposalID(receipt)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if id != "" {
+		if err := w.voter.Vote(id, admin.Approve); err != nil {
+			return 0, 0, false, fmt.Errorf("vote %s: %w", id, err)
+		}
+		approve = time.Since(created)
+		proposed = true
+	}
+	if err := w.waitStatus(ctx, s, s.id(c)); err != nil {
+		return 0, 0, false, err
+	}
+	return approve, time.Since(created), proposed, nil
+}
+
+// remember records the object registered by s in the manifest of the run, the objects
+// left registered by a cut cycle can then be logged out
+func (w *worker) remember(s *step, c *cycle) {
+	if !strings.HasPrefix(s.method, "Register") {
+		return
+	}
+	owner := c.key
+	if s.admin {
+		owner = nil
+	}
+	if err := w.g.created.Record(s.target, s.id(c), owner); err != nil {
+		log.WithField("error", err).Warnf("record %s %s", s.target, s.id(c))
+	}
+}
+
+// invoke sends the tx of the step with the governance admin, or with the cycle key set on the client
+func (w *worker) invoke(s *step, args []*pb.Arg) (*pb.Receipt, error) {
+	if !s.admin {
+		return w.client.InvokeBVMContract(contracts[s.target], s.method, nil, args...)
+	}
+	opts, err := w.g.admins.GovernorOpts(w.client)
+	if err != nil {
+		return nil, err
+	}
+	return w.client.InvokeBVMContract(contracts[s.target], s.method, opts, args...)
+}
+
+// waitStatus polls the object until it reaches the final status of the step
+func (w *worker) waitStatus(ctx context.Context, s *step, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, StatusTimeout)
+	defer cancel()
+	if err := admin.WaitStatus(ctx, w.client, s.final, contracts[s.target], s.query, rpcx.String(id)); err != nil {
+		return fmt.Errorf("%s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/crypto/asym"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/appchain"
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/reservoir"
+	"github.com/sirupsen/logrus"
+)
+
+// Type is the tx type of the governance workload
+const Type = "governance"
+
+// targets of the governance workload
+const (
+	Appchain = "appchain"
+	Service  = "service"
+	Node     = "node"
+	Role     = "role"
+	Dapp     = "dapp"
+)
+
+// AllTargets are the targets in the order their steps run in a cycle
+var AllTargets = []string{Appchain, Service, Node, Role, Dapp}
+
+// dependencies are the targets a target needs to exist first
+var dependencies = map[string][]string{
+	Service: {Appchain},
+	Node:    {Appchain}, // the nvp node permits the appchain
+	Role:    {Appchain, Node},
+}
+
+// MaxLatencySamples bounds the latencies kept per step for percentiles, they are a uniform
+// sample of all latencies, the average and the max are over all of them
+const MaxLatencySamples = reservoir.MaxSamples
+
+// fund is the amount transferred to the appchain admin of every cycle
+const fund = "100"
+
+var log = logrus.New()
+
+// Config is the configuration of the governance workload
+type Config struct {
+	Concurrent  int
+	Duration    int // s uint
+	Targets     []string
+	KeyPath     string
+	BitxhubAddr []string
+	Appchain    *appchain.Profile
+	TrustRoot   []byte
+}
+
+// ParseTargets parses a comma separated list of targets, empty for all, and adds the
+// targets they depend on
+func ParseTargets(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return AllTargets, nil
+	}
+	selected := make(map[string]bool)
+	for _, target := range strings.Split(list, ",") {
+		target = strings.TrimSpace(target)
+		known := false
+		for _, t := range AllTargets {
+			known = known || t == target
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported governance target %s, supported: %s", target, strings.Join(AllTargets, ", "))
+		}
+		selected[target] = true
+		for _, dependency := range dependencies[target] {
+			selected[dependency] = true
+		}
+	}
+	targets := make([]string, 0, len(selected))
+	for _, t := range AllTargets {
+		if selected[t] {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// Governance runs proposal lifecycles of appchains, services, nodes, roles and dapps
+// concurrently and measures the latency from proposal creation to approval and to the
+// final status of the governed object
+type Governance struct {
+	config  *Config
+	admins  *admin.Set
+	funder  *admin.Admin
+	workers []*worker
+	steps   []*step
+	stats   map[string]*StepStats
+	lock    sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	cycles  int64
+	failed  int64
+	report  *Report
+	created *manifest.Manifest
+
+	dappContract []byte
+}
+
+// New prepares a client for every worker
+func New(config *Config) (*Governance, error) {
+	log.WithFields(logrus.Fields{
+		"concurrent": config.Concurrent,
+		"duration":   config.Duration,
+		"targets":    strings.Join(config.Targets, ","),
+	}).Info("Premo governance configuration")
+
+	admins, err := admin.Load()
+	if err != nil {
+		return nil, err
+	}
+	funder, err := loadFunder(config.KeyPath, admins)
+	if err != nil {
+		return nil, err
+	}
+	steps := stepsOf(config.Targets)
+	stats := make(map[string]*StepStats, len(steps))
+	for _, s := range steps {
+		stats[s.name] = &StepStats{Name: s.name, approve: reservoir.New(), final: reservoir.New()}
+	}
+
+	var contract []byte
+	for _, t := range config.Targets {
+		if t != Dapp {
+			continue
+		}
+		root, err := repo.PathRoot()
+		if err != nil {
+			return nil, err
+		}
+		if contract, err = ioutil.ReadFile(filepath.Join(root, dappContract)); err != nil {
+			return nil, fmt.Errorf("read dapp contract: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &Governance{
+		config: config,
+		admins: admins,
+		funder: funder,
+		steps:  steps,
+		stats:  stats,
+		ctx:    ctx,
+		cancel: cancel,
+
+		dappContract: contract,
+	}
+	for i := 0; i < config.Concurrent; i++ {
+		w, err := newWorker(g, config.BitxhubAddr[i%len(config.BitxhubAddr)])
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		g.workers = append(g.workers, w)
+	}
+	g.created, err = manifest.Create(Type)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("create run manifest: %w", err)
+	}
+	log.Infof("run %s records the governed objects it creates, cycles cut by the end of the run leave them behind, remove them with `premo cleanup --run %s`", g.created.ID, g.created.ID)
+	return g, nil
+}
+
+// loadFunder returns the admin funding the cycles, it shares the nonce of the admin set
+// if the key is also a voter
+func loadFunder(keyPath string, admins *admin.Set) (*admin.Admin, error) {
+	pk, err := asym.RestorePrivateKey(keyPath, repo.KeyPassword)
+	if err != nil {
+		return nil, err
+	}
+	from, err := pk.PublicKey().Address()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range admins.Admins {
+		if a.Address.String() == from.String() {
+			return a, nil
+		}
+	}
+	return &admin.Admin{Path: keyPath, Weight: 0, Key: pk, Address: from}, nil
+}
+
+// Start runs cycles on every worker until the duration elapses or Stop is called
+func (g *Governance) Start() error {
+	log.Info("starting governance workload")
+	current := time.Now()
+	ctx, cancel := context.WithTimeout(g.ctx, time.Duration(g.config.Duration)*time.Second)
+	defer cancel()
+	defer g.created.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(len(g.workers))
+	for _, w := range g.workers {
+		go func(w *worker) {
+			defer wg.Done()
+			w.run(ctx)
+		}(w)
+	}
+	wg.Wait()
+	for _, w := range g.workers {
+		_ = w.client.Stop()
+	}
+	if g.ctx.Err() != nil {
+		return nil
+	}
+	g.report = g.newReport(time.Since(current))
+	g.report.print()
+	return nil
+}
+
+// Stop interrupts the running cycles
+func (g *Governance) Stop() {
+	g.cancel()
+}
+
+// Report returns the summary of the finished workload, it is nil if the workload was interrupted
+func (g *Governance) Report() *Report {
+	return g.report
+}
+
+func (g *Governance) record(name string, approve, final time.Duration, proposed bool, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	stats := g.stats[name]
+	if err != nil {
+		stats.Failed++
+		if stats.LastError == "" || stats.Failed%100 == 1 {
+			stats.LastError = err.Error()
+		}
+		return
+	}
+	stats.Count++
+	if proposed {
+		stats.approve.Add(approve)
+	}
+	stats.final.Add(final)
+}
+
+// transfer funds the account from the funding admin
+func (g *Governance) transfer(client rpcx.Client, to *types.Address) error {
+	data := &pb.TransactionData{Amount: fund + "000000000000000000"}
+	payload, err := data.Marshal()
+	if err != nil {
+		return err
+	}
+	nonce, err := g.funder.NextNonce(client)
+	if err != nil {
+		return err
+	}
+	tx := &pb.BxhTransaction{
+		From:      g.funder.Address,
+		To:        to,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	}
+	ret, err := client.SendTransactionWithReceipt(tx, &rpcx.TransactOpts{
+		From:    g.funder.Address.String(),
+		Nonce:   nonce,
+		PrivKey: g.funder.Key,
+	})
+	if err != nil {
+		return err
+	}
+	if ret.Status != pb.Receipt_SUCCESS {
+		return fmt.Errorf("fund %s: %s", to.String(), string(ret.Ret))
+	}
+	return nil
+}
+
+// Report is the summary of a finished governance workload
+type Report struct {
+	Duration     time.Duration `json:"duration"`
+	Cycles       int64         `json:"cycles"`
+	FailedCycles int64         `json:"failed_cycles"`
+	Proposals    int64         `json:"proposals"`
+	Throughput   float64       `json:"throughput"` // approved proposals per second
+	Steps        []*StepStats  `json:"steps"`
+}
+
+// Latency is the latency distribution of a step
+type Latency struct {
+	Avg time.Duration `json:"avg"`
+	P50 time.Duration `json:"p50"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// StepStats is the statistics of one step of the cycle, e.g. appchain/freeze. Approve is
+// from the proposal created to approved, Final is from the proposal created to the
+// governed object seen in its final status.
+type StepStats struct {
+	Name      string  `json:"name"`
+	Count     int64   `json:"count"`
+	Failed    int64   `json:"failed"`
+	LastError string  `json:"last_error,omitempty"`
+	Approve   Latency `json:"approve"`
+	Final     Latency `json:"final"`
+
+	approve *reservoir.Latencies
+	final   *reservoir.Latencies
+}
+
+func (g *Governance) newReport(duration time.Duration) *Report {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	report := &Report{
+		Duration:     duration,
+		Cycles:       atomic.LoadInt64(&g.cycles),
+		FailedCycles: atomic.LoadInt64(&g.failed),
+	}
+	for _, s := range g.steps {
+		stats := g.stats[s.name]
+		stats.Approve = summary(stats.approve)
+		stats.Final = summary(stats.final)
+		report.Proposals += int64(stats.approve.Count())
+		report.Steps = append(report.Steps, stats)
+	}
+	if seconds := duration.Seconds(); seconds > 0 {
+		report.Throughput = float64(report.Proposals) / seconds
+	}
+	return report
+}
+
+func (r *Report) print() {
+	log.WithFields(logrus.Fields{
+		"duration":      r.Duration.Seconds(),
+		"cycles":        r.Cycles,
+		"failed_cycles": r.FailedCycles,
+		"proposals":     r.Proposals,
+		"throughput":    r.Throughput,
+	}).Info("governance report")
+	for _, s := range r.Steps {
+		entry := log.WithFields(logrus.Fields{
+			"step":              s.Name,
+			"count":             s.Count,
+			"failed":            s.Failed,
+			"approve_avg":       s.Approve.Avg.String(),
+			"approve_p99":       s.Approve.P99.String(),
+			"final_avg":         s.Final.Avg.String(),
+			"final_p99":         s.Final.P99.String(),
+			"final_max_latency": s.Final.Max.String(),
+		})
+		if s.LastError != "" {
+			entry = entry.WithField("last_error", s.LastError)
+		}
+		entry.Info("governance step report")
+	}
+}
+
+// summary summarizes the latencies of a step
+func summary(l *reservoir.Latencies) Latency {
+	ps := l.Percentiles(50, 99)
+	return Latency{
+		Avg: l.Mean(),
+		P50: ps[0],
+		P99: ps[1],
+		Max: l.Max(),
+	}
+}
@@ -0,0 +1,36 @@
+package governance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"empty is all", "", AllTargets, false},
+		{"blank is all", "  ", AllTargets, false},
+		{"no dependency", "appchain", []string{Appchain}, false},
+		{"service needs appchain", "service", []string{Appchain, Service}, false},
+		{"role needs appchain and node", "role", []string{Appchain, Node, Role}, false},
+		{"cycle order and spaces", " dapp , service", []string{Appchain, Service, Dapp}, false},
+		{"duplicates", "node,node,appchain", []string{Appchain, Node}, false},
+		{"unknown", "appchain,rule", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTargets(tt.list)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	bxhgov "github.com/meshplus/bitxhub-core/governance"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StatusTimeout bounds the wait for a logged out object to reach its final status
+	StatusTimeout = 30 * time.Second
+
+	reason = "premo cleanup"
+)
+
+var log = logrus.New()
+
+// governance is how the objects of a kind are logged out and queried
+type governance struct {
+	contract *types.Address
+	logout   string
+	query    string
+	admin    bool                    // logged out by the governance admin instead of the owner
+	final    bxhgov.GovernanceStatus // status once logged out, forbidden if empty
+	args     func(id string) []*pb.Arg
+	noReason bool // the logout method takes no reason
+}
+
+var kinds = map[string]*governance{
+	Service: {contract: constant.ServiceMgrContractAddr.Address(), logout: "LogoutService", query: "GetServiceInfo"},
+	// dapps cannot be logged out, they are frozen
+	Dapp:     {contract: constant.DappMgrContractAddr.Address(), logout: "FreezeDapp", query: "GetDapp", admin: true, final: bxhgov.GovernanceFrozen},
+	Role:     {contract: constant.RoleContractAddr.Address(), logout: "LogoutRole", query: "GetRoleInfoById", admin: true},
+	Node:     {contract: constant.NodeManagerContractAddr.Address(), logout: "LogoutNode", query: "GetNode", admin: true},
+	Appchain: {contract: constant.AppchainMgrContractAddr.Address(), logout: "LogoutAppchain", query: "GetAppchain"},
+	Rule:     {contract: constant.RuleManagerContractAddr.Address(), logout: "LogoutRule", query: "GetRuleByAddr", args: ruleArgs, noReason: true},
+}
+
+// ruleArgs splits the rule id into the chain id and the rule address
+func ruleArgs(id string) []*pb.Arg {
+	var args []*pb.Arg
+	for _, part := range strings.SplitN(id, ":", 2) {
+		args = append(args, rpcx.String(part))
+	}
+	return args
+}
+
+func (g *governance) ids(id string) []*pb.Arg {
+	if g.args != nil {
+		return g.args(id)
+	}
+	return []*pb.Arg{rpcx.String(id)}
+}
+
+func (g *governance) finalStatus() bxhgov.GovernanceStatus {
+	if g.final != "" {
+		return g.final
+	}
+	return bxhgov.GovernanceForbidden
+}
+
+// Summary counts the objects of one kind handled by a cleanup
+type Summary struct {
+	Kind      string
+	LoggedOut int
+	Skipped   int // already logged out, or never registered
+	Failed    int
+}
+
+// Cleanup logs out the objects recorded by run in dependency order and approves the
+// proposals with the admins, objects already logged out are skipped so a cleanup can be
+// run again after a failure
+func Cleanup(run *Run, bitxhubAddr string) ([]*Summary, error) {
+	admins, err := admin.Load()
+	if err != nil {
+		return nil, err
+	}
+	pk, _, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: bitxhubAddr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Stop()
+	voter := admin.NewVoter(client, admins, reason)
+
+	var summaries []*Summary
+	for _, kind := range CleanupOrder {
+		summary := &Summary{Kind: kind}
+		for _, entry := range run.Entries {
+			if entry.Kind != kind {
+				continue
+			}
+			done, err := logout(client, admins, voter, run, entry)
+			switch {
+			case err != nil:
+				summary.Failed++
+				log.WithField("error", err).Warnf("logout %s %s", kind, entry.ID)
+			case done:
+				summary.LoggedOut++
+			default:
+				summary.Skipped++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// logout logs the object of entry out, it reports false if there was nothing to do
+func logout(client rpcx.Client, admins *admin.Set, voter *admin.Voter, run *Run, entry *Entry) (bool, error) {
+	g, ok := kinds[entry.Kind]
+	if !ok {
+		return false, fmt.Errorf("unsupported kind %s", entry.Kind)
+	}
+	obj, err := admin.GetObject(client, g.contract, g.query, g.ids(entry.ID)...)
+	if err != nil {
+		// a register tx recorded but never packed leaves nothing behind
+		log.WithField("error", err).Debugf("query %s %s", entry.Kind, entry.ID)
+		return false, nil
+	}
+	switch obj.Status {
+	case g.finalStatus(), bxhgov.GovernanceForbidden, bxhgov.GovernanceUnavailable:
+		return false, nil
+	}
+
+	opts, err := sender(client, admins, run, g, entry)
+	if err != nil {
+		return false, err
+	}
+	args := g.ids(entry.ID)
+	if !g.noReason {
+		args = append(args, rpcx.String(reason))
+	}
+	receipt, err := client.InvokeBVMContract(g.contract, g.logout, opts, args...)
+	if err != nil {
+		return false, err
+	}
+	if receipt.Status != pb.Receipt_SUCCESS {
+		return false, fmt.Errorf("%s is %s: %s", g.logout, obj.Status, string(receipt.Ret))
+	}
+	id, err := admin.ProposalID(receipt)
+	if err != nil {
+		return false, err
+	}
+	if id != "" {
+		if err := voter.Vote(id, admin.Approve); err != nil {
+			return false, fmt.Errorf("vote %s: %w", id, err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+	if err := admin.WaitStatus(ctx, client, g.finalStatus(), g.contract, g.query, g.ids(entry.ID)...); err != nil {
+		return true, fmt.Errorf("%s: %w", entry.ID, err)
+	}
+	return true, nil
+}
+
+// sender returns the options of the logout tx, signed by the owner or the governor
+func sender(client rpcx.Client, admins *admin.Set, run *Run, g *governance, entry *Entry) (*rpcx.TransactOpts, error) {
+	if g.admin || entry.Owner == "" {
+		return admins.GovernorOpts(client)
+	}
+	key, err := run.Key(entry.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("key of %s: %w", entry.Owner, err)
+	}
+	nonce, err := client.GetPendingNonceByAccount(entry.Owner)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcx.TransactOpts{From: entry.Owner, Nonce: nonce, PrivKey: key}, nil
+}
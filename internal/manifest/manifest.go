@@ -0,0 +1,221 @@
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-kit/crypto/asym"
+	"github.com/meshplus/bitxhub-kit/fileutil"
+	"github.com/meshplus/premo/internal/repo"
+)
+
+// kinds of the governed objects recorded by a run
+const (
+	Appchain = "appchain"
+	Service  = "service"
+	Node     = "node"
+	Role     = "role"
+	Dapp     = "dapp"
+	Rule     = "rule" // id is <chain id>:<rule address>
+)
+
+// CleanupOrder are the kinds in the order they are logged out, an object goes before
+// the objects it depends on. The master rule of an appchain is cleared with it, only the
+// rules left unbound by a cut run are logged out after the appchains.
+var CleanupOrder = []string{Service, Dapp, Role, Node, Appchain, Rule}
+
+const (
+	// RunsDir is the dir of the run manifests in the repo
+	RunsDir = "runs"
+
+	entriesFile = "manifest.jsonl"
+	timeLayout  = "20060102-150405"
+)
+
+// Entry is one governed object created by a run
+type Entry struct {
+	Kind    string    `json:"kind"`
+	ID      string    `json:"id"`
+	Owner   string    `json:"owner,omitempty"` // account logging the object out, empty for the governance admin
+	Created time.Time `json:"created"`
+}
+
+// Manifest records the governed objects a run creates, every entry is appended to the
+// file as soon as it is recorded so an interrupted run leaves a complete manifest. The
+// keys of the owners are stored next to it, encrypted with the repo password.
+type Manifest struct {
+	ID string
+
+	dir  string
+	lock sync.Mutex
+	file *os.File
+	keys map[string]bool
+}
+
+// Create starts the manifest of a new run of command
+func Create(command string) (*Manifest, error) {
+	root, err := repo.PathRootWithDefault()
+	if err != nil {
+		return nil, err
+	}
+	runs := filepath.Join(root, RunsDir)
+	if err := os.MkdirAll(runs, 0755); err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("%s-%s", command, time.Now().Format(timeLayout))
+	dir := filepath.Join(runs, id)
+	// runs started in the same second get a suffix
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		id = fmt.Sprintf("%s-%s-%d", command, time.Now().Format(timeLayout), i)
+		dir = filepath.Join(runs, id)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, entriesFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{ID: id, dir: dir, file: file, keys: make(map[string]bool)}, nil
+}
+
+// RuleID returns the id of the rule at addr of the appchain chainID
+func RuleID(chainID, addr string) string {
+	return chainID + ":" + addr
+}
+
+// Record appends a created object, owner is the key logging it out, nil for the governance admin
+func (m *Manifest) Record(kind, id string, owner crypto.PrivateKey) error {
+	entry := &Entry{Kind: kind, ID: id, Created: time.Now()}
+	if owner != nil {
+		addr, err := owner.PublicKey().Address()
+		if err != nil {
+			return err
+		}
+		entry.Owner = addr.String()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if owner != nil && !m.keys[entry.Owner] {
+		if err := storeKey(owner, m.keyPath(entry.Owner)); err != nil {
+			return fmt.Errorf("store key of %s: %w", entry.Owner, err)
+		}
+		m.keys[entry.Owner] = true
+	}
+	_, err = m.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the manifest file, the recorded entries are kept
+func (m *Manifest) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.file.Close()
+}
+
+func (m *Manifest) keyPath(owner string) string {
+	return filepath.Join(m.dir, owner+".json")
+}
+
+// storeKey writes the owner key readable by the user only, like every premo key it is
+// encrypted with the well-known repo.KeyPassword, so the file mode is its only protection
+func storeKey(owner crypto.PrivateKey, path string) error {
+	keyStore, err := asym.GenKeyStore(owner, repo.KeyPassword)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keyStore, "", " ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// Run is a recorded run loaded for cleanup
+type Run struct {
+	ID      string
+	Entries []*Entry
+
+	dir string
+}
+
+// Load loads the manifest of the run id
+func Load(id string) (*Run, error) {
+	root, err := repo.PathRootWithDefault()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(root, RunsDir, id)
+	path := filepath.Join(dir, entriesFile)
+	if !fileutil.Exist(path) {
+		return nil, fmt.Errorf("run %s is not recorded in %s", id, filepath.Join(root, RunsDir))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	run := &Run{ID: id, dir: dir}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := &Entry{}
+		// a run killed while writing leaves a torn last line
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			continue
+		}
+		run.Entries = append(run.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Key returns the key of the owner of an entry
+func (r *Run) Key(owner string) (crypto.PrivateKey, error) {
+	return asym.RestorePrivateKey(filepath.Join(r.dir, owner+".json"), repo.KeyPassword)
+}
+
+// List returns the IDs of the recorded runs, the oldest first
+func List() ([]string, error) {
+	root, err := repo.PathRootWithDefault()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(filepath.Join(root, RunsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	var ids []string
+	for _, info := range infos {
+		if info.IsDir() {
+			ids = append(ids, info.Name())
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,557 @@
+package preload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-kit/crypto/asym"
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/bitxhub"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/tps"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultBatchSize = 200
+	// MaxAttempts bounds the sends of one chunk before the preload stops, it can be resumed
+	MaxAttempts = 5
+	// ReceiptTimeout bounds the wait for the receipt confirming a chunk
+	ReceiptTimeout = 2 * time.Minute
+
+	maxUnconfirmed      = 16 // chunks a sender sends ahead of their receipts
+	receiptPollInterval = 500 * time.Millisecond
+	seedSize            = 16
+	retryBackoff        = time.Second
+	progressInterval    = 5 * time.Second
+)
+
+var log = logrus.New()
+
+// Config is a new preload, or the way a preload is resumed
+type Config struct {
+	Resume      string // id of the preload to resume, the plan fields are then taken from it
+	Accounts    uint64
+	Keys        uint64
+	ValueSize   int
+	Balance     string // smallest unit transferred to every account
+	BatchSize   int
+	Concurrent  int // sender accounts, each sends its chunks back to back
+	KeyPath     string
+	BitxhubAddr []string
+}
+
+// Preloader creates the accounts and keys of a plan through funded sender accounts
+type Preloader struct {
+	config   *Config
+	progress *Progress
+	dir      string
+	seed     []byte
+	client   rpcx.Client
+	senders  []*sender
+	marks    *watermark
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// sentChunk is a chunk accepted by a node and waiting for its receipt
+type sentChunk struct {
+	index uint64
+	last  string // hash of the last tx
+}
+
+type sender struct {
+	pk     crypto.PrivateKey
+	from   *types.Address
+	client rpcx.Client
+	nonce  uint64
+	values *mrand.Rand
+}
+
+// New plans a preload, or loads the one to resume, and funds its sender accounts
+func New(config *Config) (*Preloader, error) {
+	var (
+		progress *Progress
+		dir      string
+		err      error
+	)
+	if config.Resume != "" {
+		progress, dir, err = load(config.Resume)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("resuming preload %s at %d of %d txs", progress.ID, progress.Done, progress.items())
+	} else {
+		progress, dir, err = plan(config)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("starting preload %s", progress.ID)
+	}
+	if progress.Balance == "" {
+		progress.Balance = "0"
+	}
+	balance, ok := new(big.Int).SetString(progress.Balance, 10)
+	if !ok || balance.Sign() < 0 {
+		return nil, fmt.Errorf("invalid balance %s", progress.Balance)
+	}
+	seed, err := progress.seed()
+	if err != nil {
+		return nil, fmt.Errorf("decode seed of preload %s: %w", progress.ID, err)
+	}
+
+	funderPk, err := asym.RestorePrivateKey(config.KeyPath, repo.KeyPassword)
+	if err != nil {
+		return nil, err
+	}
+	funderFrom, err := funderPk.PublicKey().Address()
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClient(config.BitxhubAddr[0], funderPk)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Preloader{
+		config:   config,
+		progress: progress,
+		dir:      dir,
+		seed:     seed,
+		client:   client,
+		marks:    newWatermark(progress.Done / uint64(progress.BatchSize)),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if err := p.prepareSenders(funderPk, funderFrom, balance); err != nil {
+		cancel()
+		p.stopClients()
+		return nil, err
+	}
+	return p, nil
+}
+
+// plan makes the plan of a new preload and records it before any tx is sent
+func plan(config *Config) (*Progress, string, error) {
+	if config.Accounts+config.Keys == 0 {
+		return nil, "", fmt.Errorf("nothing to preload, set accounts or keys")
+	}
+	id, dir, err := create()
+	if err != nil {
+		return nil, "", err
+	}
+	seed := make([]byte, seedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, "", err
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	progress := &Progress{
+		Plan: Plan{
+			ID:        id,
+			Seed:      hex.EncodeToString(seed),
+			Accounts:  config.Accounts,
+			Keys:      config.Keys,
+			ValueSize: config.ValueSize,
+			Balance:   config.Balance,
+			BatchSize: batchSize,
+		},
+		Started: time.Now(),
+		Updated: time.Now(),
+	}
+	if err := writeJSON(dir, progressFile, progress); err != nil {
+		return nil, "", err
+	}
+	return progress, dir, nil
+}
+
+func newClient(addr string, pk crypto.PrivateKey) (rpcx.Client, error) {
+	return rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: addr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+}
+
+// prepareSenders creates the sender accounts, spread over the nodes, and funds each with
+// the balances it transfers and bitxhub.BeeFund tokens for the fees
+func (p *Preloader) prepareSenders(funderPk crypto.PrivateKey, funderFrom *types.Address, balance *big.Int) error {
+	n := p.config.Concurrent
+	if n <= 0 {
+		n = 1
+	}
+	fee, _ := new(big.Int).SetString(bitxhub.BeeFund+"000000000000000000", 10)
+	funds := make([]*big.Int, n)
+	need := new(big.Int)
+	for k := range funds {
+		funds[k] = new(big.Int).Mul(balance, new(big.Int).SetUint64(p.transfers(uint64(k), uint64(n))))
+		funds[k].Add(funds[k], fee)
+		need.Add(need, funds[k])
+	}
+	have, err := bitxhub.Balance(p.client, funderFrom.String())
+	if err != nil {
+		return err
+	}
+	if have.Cmp(need) < 0 {
+		return fmt.Errorf("%s has %s but the senders need %s, lower the balance or top up the account", funderFrom.String(), have, need)
+	}
+
+	nonce, err := p.client.GetPendingNonceByAccount(funderFrom.String())
+	if err != nil {
+		return err
+	}
+	for k := 0; k < n; k++ {
+		pk, from, err := repo.KeyPriv()
+		if err != nil {
+			return err
+		}
+		client, err := newClient(p.config.BitxhubAddr[k%len(p.config.BitxhubAddr)], pk)
+		if err != nil {
+			return err
+		}
+		if err := transfer(p.client, funderPk, funderFrom, nonce, from, funds[k]); err != nil {
+			return fmt.Errorf("fund sender %s: %w", from.String(), err)
+		}
+		nonce++
+		senderNonce, err := client.GetPendingNonceByAccount(from.String())
+		if err != nil {
+			return err
+		}
+		p.senders = append(p.senders, &sender{
+			pk:     pk,
+			from:   from,
+			client: client,
+			nonce:  senderNonce,
+			values: mrand.New(mrand.NewSource(time.Now().UnixNano() + int64(k))),
+		})
+	}
+	log.WithFields(logrus.Fields{
+		"senders": n,
+		"funds":   need.String(),
+	}).Info("funded senders")
+	return nil
+}
+
+// transfers returns the number of transfers sender k of n sends for the rest of the plan
+func (p *Preloader) transfers(k, n uint64) uint64 {
+	var count uint64
+	batch := uint64(p.progress.BatchSize)
+	for c := p.progress.Done/batch + k; c*batch < p.progress.Accounts; c += n {
+		end := (c + 1) * batch
+		if end > p.progress.Accounts {
+			end = p.progress.Accounts
+		}
+		count += end - c*batch
+	}
+	return count
+}
+
+// Start sends the chunks of the plan not done yet, the senders take them in turn. A chunk
+// is done once the receipt of its last tx succeeds, the progress is saved every
+// progressInterval, and the manifest once the txs drain or the preload stops.
+func (p *Preloader) Start() error {
+	if p.progress.FirstHeight == 0 {
+		meta, err := p.client.GetChainMeta()
+		if err != nil {
+			return err
+		}
+		p.progress.FirstHeight = meta.Height
+	}
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs []error
+	)
+	fail := func(err error) {
+		lock.Lock()
+		errs = append(errs, err)
+		lock.Unlock()
+		// the chunks above stay undone, the preload can be resumed from the watermark
+		p.cancel()
+	}
+	first := p.progress.Done / uint64(p.progress.BatchSize)
+	n := uint64(len(p.senders))
+	for k, s := range p.senders {
+		wg.Add(1)
+		go func(k uint64, s *sender) {
+			defer wg.Done()
+			sent := make(chan *sentChunk, maxUnconfirmed)
+			confirmed := make(chan struct{})
+			go func() {
+				defer close(confirmed)
+				failed := false
+				for chunk := range sent {
+					if failed {
+						continue
+					}
+					if err := confirm(s, chunk.last); err != nil {
+						failed = true
+						fail(fmt.Errorf("confirm chunk %d: %w", chunk.index, err))
+						continue
+					}
+					p.marks.complete(chunk.index)
+				}
+			}()
+			defer func() {
+				close(sent)
+				<-confirmed
+			}()
+
+			for c := first + k; c < p.progress.chunks(); c += n {
+				if p.ctx.Err() != nil {
+					return
+				}
+				last, err := p.sendChunk(s, c)
+				if err != nil {
+					if !errors.Is(err, context.Canceled) {
+						fail(err)
+					}
+					return
+				}
+				sent <- &sentChunk{index: c, last: last}
+			}
+		}(uint64(k), s)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	last := p.progress.Done
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		case <-ticker.C:
+		}
+		if err := p.save(); err != nil {
+			log.WithField("error", err).Warn("save preload progress")
+		}
+		log.Infof("preloaded %d of %d txs, %.0f txs/s", p.progress.Done, p.progress.items(),
+			float64(p.progress.Done-last)/progressInterval.Seconds())
+		last = p.progress.Done
+	}
+
+	log.Info("Waiting for the preloaded txs to drain, please wait...")
+	height, err := tps.Drain(p.client, &tps.Config{}, nil)
+	if err != nil {
+		log.WithField("error", err).Warn("drain the preloaded txs")
+	}
+	if err := p.writeManifest(height); err != nil {
+		return err
+	}
+	p.stopClients()
+	if len(errs) != 0 {
+		return fmt.Errorf("preload %s stopped at %d of %d txs, resume it with --resume %s: %w",
+			p.progress.ID, p.progress.Done, p.progress.items(), p.progress.ID, errs[0])
+	}
+	if p.progress.Done < p.progress.items() {
+		log.Infof("preload %s stopped at %d of %d txs, resume it with --resume %s",
+			p.progress.ID, p.progress.Done, p.progress.items(), p.progress.ID)
+	}
+	return nil
+}
+
+// Stop stops sending, the progress and the manifest are still written by Start
+func (p *Preloader) Stop() {
+	p.cancel()
+}
+
+// ID returns the id of the preload
+func (p *Preloader) ID() string {
+	return p.progress.ID
+}
+
+// sendChunk sends the txs of chunk c and returns the hash of the last one, after a failure
+// the chunk is signed again from the pending nonce of the node
+func (p *Preloader) sendChunk(s *sender, c uint64) (string, error) {
+	batch := uint64(p.progress.BatchSize)
+	begin, end := c*batch, (c+1)*batch
+	if end > p.progress.items() {
+		end = p.progress.items()
+	}
+	for attempt := 1; ; attempt++ {
+		txs := make([]*pb.BxhTransaction, 0, end-begin)
+		for i := begin; i < end; i++ {
+			tx, err := p.genTx(s, i, s.nonce+i-begin)
+			if err != nil {
+				return "", err
+			}
+			txs = append(txs, tx)
+		}
+		_, err := s.client.SendTransactions(&pb.MultiTransaction{Txs: txs})
+		if err == nil {
+			s.nonce += end - begin
+			return txs[len(txs)-1].GetHash().String(), nil
+		}
+		if attempt >= MaxAttempts {
+			return "", fmt.Errorf("send chunk %d by %s: %w", c, s.from.String(), err)
+		}
+		log.WithFields(logrus.Fields{
+			"chunk":   c,
+			"attempt": attempt,
+		}).Warnf("send preload txs: %s", err)
+		select {
+		case <-p.ctx.Done():
+			return "", p.ctx.Err()
+		case <-time.After(retryBackoff * time.Duration(attempt)):
+		}
+		nonce, err := s.client.GetPendingNonceByAccount(s.from.String())
+		if err != nil {
+			return "", err
+		}
+		s.nonce = nonce
+	}
+}
+
+// confirm waits for the receipt of the tx hash. The txs of a sender are packed in nonce
+// order, so the receipt of the last tx of a chunk confirms the whole chunk.
+func confirm(s *sender, hash string) error {
+	deadline := time.Now().Add(ReceiptTimeout)
+	for {
+		receipt, err := s.client.GetReceipt(hash)
+		if err == nil {
+			if receipt.Status != pb.Receipt_SUCCESS {
+				return fmt.Errorf("tx %s failed: %s", hash, string(receipt.Ret))
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("no receipt of tx %s after %s: %w", hash, ReceiptTimeout, err)
+		}
+		time.Sleep(receiptPollInterval)
+	}
+}
+
+// genTx returns the tx of item i, a transfer to an account or a key stored by the store contract
+func (p *Preloader) genTx(s *sender, i, nonce uint64) (*pb.BxhTransaction, error) {
+	var (
+		to   *types.Address
+		data *pb.TransactionData
+	)
+	if i < p.progress.Accounts {
+		to = Address(p.seed, i)
+		data = &pb.TransactionData{
+			Type:   pb.TransactionData_NORMAL,
+			VmType: pb.TransactionData_XVM,
+			Amount: p.progress.Balance,
+		}
+	} else {
+		pl := &pb.InvokePayload{
+			Method: "Set",
+			Args:   []*pb.Arg{rpcx.String(Key(p.progress.ID, i-p.progress.Accounts)), rpcx.String(s.value(p.progress.ValueSize))},
+		}
+		payload, err := pl.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		to = constant.StoreContractAddr.Address()
+		data = &pb.TransactionData{
+			Type:    pb.TransactionData_INVOKE,
+			VmType:  pb.TransactionData_BVM,
+			Payload: payload,
+		}
+	}
+	payload, err := data.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	tx := &pb.BxhTransaction{
+		From:      s.from,
+		To:        to,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+		Nonce:     nonce,
+	}
+	if err := tx.Sign(s.pk); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// value returns size random letters, so the values do not compress away in the state db
+func (s *sender) value(size int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = letters[s.values.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// save records the txs below the watermark as done
+func (p *Preloader) save() error {
+	done := p.marks.chunks() * uint64(p.progress.BatchSize)
+	if done > p.progress.items() {
+		done = p.progress.items()
+	}
+	p.progress.Done = done
+	p.progress.Updated = time.Now()
+	return writeJSON(p.dir, progressFile, p.progress)
+}
+
+func (p *Preloader) writeManifest(last uint64) error {
+	accounts, keys := p.progress.created()
+	return writeJSON(p.dir, manifestFile, &Manifest{
+		Plan:            p.progress.Plan,
+		CreatedAccounts: accounts,
+		CreatedKeys:     keys,
+		Complete:        p.progress.Done == p.progress.items(),
+		Derivation:      Derivation,
+		FirstHeight:     p.progress.FirstHeight,
+		LastHeight:      last,
+		Started:         p.progress.Started,
+		Finished:        time.Now(),
+	})
+}
+
+func (p *Preloader) stopClients() {
+	for _, s := range p.senders {
+		_ = s.client.Stop()
+	}
+	_ = p.client.Stop()
+}
+
+// transfer sends amount from the funder and waits for the receipt
+func transfer(client rpcx.Client, pk crypto.PrivateKey, from *types.Address, nonce uint64, to *types.Address, amount *big.Int) error {
+	data := &pb.TransactionData{Amount: amount.String()}
+	payload, err := data.Marshal()
+	if err != nil {
+		return err
+	}
+	ret, err := client.SendTransactionWithReceipt(&pb.BxhTransaction{
+		From:      from,
+		To:        to,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payload,
+	}, &rpcx.TransactOpts{
+		From:    from.String(),
+		Nonce:   nonce,
+		PrivKey: pk,
+	})
+	if err != nil {
+		return err
+	}
+	if ret.Status != pb.Receipt_SUCCESS {
+		return fmt.Errorf(string(ret.Ret))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package preload
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/premo/internal/repo"
+)
+
+const (
+	// Dir is the dir of the preloads in the repo
+	Dir = "preloads"
+
+	progressFile = "progress.json"
+	manifestFile = "manifest.json"
+	timeLayout   = "20060102-150405"
+)
+
+// Derivation tells how the accounts and keys of a preload are named, a benchmark can
+// pick them from the seed and the counts of the manifest
+const Derivation = "account i is the first 20 bytes of sha256(seed | big endian uint64 i), " +
+	"key i is preload-<id>-<i> in the store contract"
+
+// Plan is what a preload creates, it is kept with the progress so a resumed preload
+// creates the same accounts and keys
+type Plan struct {
+	ID        string `json:"id"`
+	Seed      string `json:"seed"` // hex
+	Accounts  uint64 `json:"accounts"`
+	Keys      uint64 `json:"keys"`
+	ValueSize int    `json:"value_size"`
+	Balance   string `json:"balance"` // smallest unit transferred to every account
+	BatchSize int    `json:"batch_size"`
+}
+
+// items returns the number of txs of the plan, the transfers to the accounts come first
+func (p *Plan) items() uint64 {
+	return p.Accounts + p.Keys
+}
+
+func (p *Plan) chunks() uint64 {
+	return (p.items() + uint64(p.BatchSize) - 1) / uint64(p.BatchSize)
+}
+
+// Progress is how far a preload got, every chunk below Done was committed, the last tx
+// of each with a success receipt
+type Progress struct {
+	Plan
+	Done        uint64    `json:"done"`
+	FirstHeight uint64    `json:"first_height"` // chain height before the first tx
+	Started     time.Time `json:"started"`
+	Updated     time.Time `json:"updated"`
+}
+
+// created returns the accounts and keys the txs below done create
+func (p *Progress) created() (uint64, uint64) {
+	if p.Done <= p.Accounts {
+		return p.Done, 0
+	}
+	return p.Accounts, p.Done - p.Accounts
+}
+
+// Manifest is what a preload created, written when the preload stops
+type Manifest struct {
+	Plan
+	CreatedAccounts uint64    `json:"created_accounts"`
+	CreatedKeys     uint64    `json:"created_keys"`
+	Complete        bool      `json:"complete"`
+	Derivation      string    `json:"derivation"`
+	FirstHeight     uint64    `json:"first_height"` // chain height before the first tx
+	LastHeight      uint64    `json:"last_height"`  // chain height once the txs drained
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+}
+
+// Address returns the account i of a preload with seed
+func Address(seed []byte, i uint64) *types.Address {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], i)
+	sum := sha256.Sum256(append(append([]byte{}, seed...), index[:]...))
+	return types.NewAddress(sum[:types.AddressLength])
+}
+
+// Key returns the store key i of the preload id
+func Key(id string, i uint64) string {
+	return fmt.Sprintf("preload-%s-%d", id, i)
+}
+
+// create makes the dir of a new preload and returns its id
+func create() (string, string, error) {
+	root, err := repo.PathRootWithDefault()
+	if err != nil {
+		return "", "", err
+	}
+	preloads := filepath.Join(root, Dir)
+	if err := os.MkdirAll(preloads, 0755); err != nil {
+		return "", "", err
+	}
+	id := time.Now().Format(timeLayout)
+	dir := filepath.Join(preloads, id)
+	// preloads started in the same second get a suffix
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", "", err
+		}
+		id = fmt.Sprintf("%s-%d", time.Now().Format(timeLayout), i)
+		dir = filepath.Join(preloads, id)
+	}
+	return id, dir, nil
+}
+
+// load reads the progress of the preload id
+func load(id string) (*Progress, string, error) {
+	root, err := repo.PathRootWithDefault()
+	if err != nil {
+		return nil, "", err
+	}
+	dir := filepath.Join(root, Dir, id)
+	data, err := ioutil.ReadFile(filepath.Join(dir, progressFile))
+	if err != nil {
+		return nil, "", fmt.Errorf("read progress of preload %s: %w", id, err)
+	}
+	progress := &Progress{}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, "", fmt.Errorf("unmarshal progress of preload %s: %w", id, err)
+	}
+	return progress, dir, nil
+}
+
+// seed decodes the seed of the plan
+func (p *Plan) seed() ([]byte, error) {
+	return hex.DecodeString(p.Seed)
+}
+
+// writeJSON replaces the file name in dir with v, the old content stays whole if writing fails
+func writeJSON(dir, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, name+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, name))
+}
+
+// watermark follows the chunks confirmed by receipts, chunks complete out of order but
+// only the ones below the first missing chunk count as done
+type watermark struct {
+	lock sync.Mutex
+	next uint64
+	done map[uint64]struct{}
+}
+
+func newWatermark(first uint64) *watermark {
+	return &watermark{next: first, done: make(map[uint64]struct{})}
+}
+
+func (w *watermark) complete(chunk uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.done[chunk] = struct{}{}
+	for {
+		if _, ok := w.done[w.next]; !ok {
+			return
+		}
+		delete(w.done, w.next)
+		w.next++
+	}
+}
+
+// chunks returns the number of chunks below the first missing one
+func (w *watermark) chunks() uint64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.next
+}
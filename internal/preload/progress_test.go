@@ -0,0 +1,60 @@
+package preload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermark(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    uint64
+		complete []uint64
+		want     uint64
+	}{
+		{"none", 0, nil, 0},
+		{"in order", 0, []uint64{0, 1, 2}, 3},
+		{"out of order", 0, []uint64{2, 0, 1}, 3},
+		{"gap", 0, []uint64{0, 2, 3}, 1},
+		{"first missing", 0, []uint64{1, 2}, 0},
+		{"resumed", 5, []uint64{6, 5}, 7},
+		{"below a resumed watermark", 5, []uint64{3}, 5},
+		{"repeated", 0, []uint64{0, 0, 1}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWatermark(tt.first)
+			for _, chunk := range tt.complete {
+				w.complete(chunk)
+			}
+			require.Equal(t, tt.want, w.chunks())
+		})
+	}
+}
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		seed   []byte
+		i      uint64
+		other  []byte
+		j      uint64
+		differ bool
+	}{
+		{"same seed and index", []byte("seed"), 1, []byte("seed"), 1, false},
+		{"other index", []byte("seed"), 1, []byte("seed"), 2, true},
+		{"other seed", []byte("seed"), 1, []byte("other"), 1, true},
+		{"index not concatenated to seed", []byte("seed"), 1 << 8, []byte("seed\x01"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Address(tt.seed, tt.i), Address(tt.other, tt.j)
+			require.Equal(t, tt.differ, a.String() != b.String())
+		})
+	}
+
+	seed := []byte("seed")
+	Address(seed, 1)
+	require.Equal(t, []byte("seed"), seed, "the seed is not modified")
+}
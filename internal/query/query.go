@@ -0,0 +1,304 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/reservoir"
+	"github.com/sirupsen/logrus"
+)
+
+// Type is the tx type of the query workload
+const Type = "query"
+
+// read calls of the query workload
+const (
+	BlockByHeight = "block_height"
+	BlockByHash   = "block_hash"
+	Blocks        = "blocks"
+	Receipt       = "receipt"
+	Transaction   = "transaction"
+	Balance       = "balance"
+	ChainMeta     = "chain_meta"
+	Appchain      = "appchain"
+	Service       = "service"
+)
+
+// AllCalls are the read calls in report order
+var AllCalls = []string{BlockByHeight, BlockByHash, Blocks, Receipt, Transaction, Balance, ChainMeta, Appchain, Service}
+
+const (
+	// DefaultBlocksRange is the number of blocks fetched by one GetBlocks
+	DefaultBlocksRange = 10
+	// MaxLatencySamples bounds the latencies kept per call for percentiles, they are a uniform
+	// sample of all latencies, the average and the max are over all of them
+	MaxLatencySamples = reservoir.MaxSamples
+)
+
+var log = logrus.New()
+
+// Config is the configuration of the query workload
+type Config struct {
+	Concurrent  int
+	Duration    int // s uint
+	Mix         map[string]int
+	BlocksRange int
+	BitxhubAddr []string
+}
+
+// ParseMix parses a comma separated list of call=weight, empty for every call with weight 1
+func ParseMix(list string) (map[string]int, error) {
+	mix := make(map[string]int)
+	if strings.TrimSpace(list) == "" {
+		for _, call := range AllCalls {
+			mix[call] = 1
+		}
+		return mix, nil
+	}
+	for _, item := range strings.Split(list, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		call, weight := kv[0], 1
+		if len(kv) == 2 {
+			w, err := strconv.Atoi(kv[1])
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight of query %s: %s", call, kv[1])
+			}
+			weight = w
+		}
+		known := false
+		for _, c := range AllCalls {
+			known = known || c == call
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported query %s, supported: %s", call, strings.Join(AllCalls, ", "))
+		}
+		mix[call] = weight
+	}
+	return mix, nil
+}
+
+// Query drives the read APIs of bitxhub with a weighted mix of calls from concurrent
+// workers and measures the qps and latency of every call
+type Query struct {
+	config  *Config
+	clients []rpcx.Client
+	seed    *seed
+	calls   []string // one entry per weight unit
+	stats   map[string]*CallStats
+	lock    sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	report  *Report
+}
+
+// New connects a client for every worker and seeds the heights, hashes and accounts to read
+func New(config *Config) (*Query, error) {
+	log.WithFields(logrus.Fields{
+		"concurrent": config.Concurrent,
+		"duration":   config.Duration,
+		"mix":        formatMix(config.Mix),
+	}).Info("Premo query configuration")
+	if config.BlocksRange <= 0 {
+		config.BlocksRange = DefaultBlocksRange
+	}
+
+	q := &Query{config: config, stats: make(map[string]*CallStats)}
+	for i := 0; i < config.Concurrent; i++ {
+		pk, _, err := repo.KeyPriv()
+		if err != nil {
+			return nil, err
+		}
+		client, err := rpcx.New(
+			rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: config.BitxhubAddr[i%len(config.BitxhubAddr)]}),
+			rpcx.WithLogger(log),
+			rpcx.WithPrivateKey(pk),
+		)
+		if err != nil {
+			return nil, err
+		}
+		q.clients = append(q.clients, client)
+	}
+
+	s, err := newSeed(q.clients[0])
+	if err != nil {
+		return nil, fmt.Errorf("seed query workload: %w", err)
+	}
+	q.seed = s
+	for _, call := range AllCalls {
+		weight := config.Mix[call]
+		if weight == 0 {
+			continue
+		}
+		if !s.supports(call) {
+			log.Warnf("no %s to read from the chain, query %s is skipped", s.needs(call), call)
+			continue
+		}
+		q.stats[call] = &CallStats{Name: call, latency: reservoir.New()}
+		for i := 0; i < weight; i++ {
+			q.calls = append(q.calls, call)
+		}
+	}
+	if len(q.calls) == 0 {
+		return nil, fmt.Errorf("no query in the mix can be sent")
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	return q, nil
+}
+
+// Start sends queries from every worker until the duration elapses or Stop is called
+func (q *Query) Start() error {
+	log.Info("starting query workload")
+	current := time.Now()
+	ctx, cancel := context.WithTimeout(q.ctx, time.Duration(q.config.Duration)*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(len(q.clients))
+	for i, client := range q.clients {
+		go func(client rpcx.Client, seed int64) {
+			defer wg.Done()
+			q.run(ctx, client, rand.New(rand.NewSource(seed)))
+		}(client, current.UnixNano()+int64(i))
+	}
+	wg.Wait()
+	for _, client := range q.clients {
+		_ = client.Stop()
+	}
+	if q.ctx.Err() != nil {
+		return nil
+	}
+	q.report = q.newReport(time.Since(current))
+	q.report.print()
+	return nil
+}
+
+// Stop interrupts the running queries
+func (q *Query) Stop() {
+	q.cancel()
+}
+
+// Report returns the summary of the finished workload, it is nil if the workload was interrupted
+func (q *Query) Report() *Report {
+	return q.report
+}
+
+func (q *Query) run(ctx context.Context, client rpcx.Client, r *rand.Rand) {
+	for ctx.Err() == nil {
+		call := q.calls[r.Intn(len(q.calls))]
+		start := time.Now()
+		err := q.seed.call(client, call, r, q.config.BlocksRange)
+		latency := time.Since(start)
+		if ctx.Err() != nil {
+			return
+		}
+		q.record(call, latency, err)
+	}
+}
+
+func (q *Query) record(call string, latency time.Duration, err error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	stats := q.stats[call]
+	if err != nil {
+		stats.Failed++
+		stats.LastError = err.Error()
+		return
+	}
+	stats.Count++
+	stats.latency.Add(latency)
+}
+
+// Report is the summary of a finished query workload
+type Report struct {
+	Duration time.Duration `json:"duration"`
+	Count    int64         `json:"count"`
+	Failed   int64         `json:"failed"`
+	QPS      float64       `json:"qps"`
+	Calls    []*CallStats  `json:"calls"`
+}
+
+// CallStats is the statistics of one read call
+type CallStats struct {
+	Name       string        `json:"name"`
+	Count      int64         `json:"count"`
+	Failed     int64         `json:"failed"`
+	LastError  string        `json:"last_error,omitempty"`
+	QPS        float64       `json:"qps"`
+	AvgLatency time.Duration `json:"avg_latency"`
+	P50Latency time.Duration `json:"p50_latency"`
+	P90Latency time.Duration `json:"p90_latency"`
+	P99Latency time.Duration `json:"p99_latency"`
+	MaxLatency time.Duration `json:"max_latency"`
+
+	latency *reservoir.Latencies
+}
+
+func (q *Query) newReport(duration time.Duration) *Report {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	report := &Report{Duration: duration}
+	seconds := duration.Seconds()
+	for _, call := range AllCalls {
+		stats, ok := q.stats[call]
+		if !ok {
+			continue
+		}
+		if seconds > 0 {
+			stats.QPS = float64(stats.Count) / seconds
+		}
+		stats.AvgLatency = stats.latency.Mean()
+		ps := stats.latency.Percentiles(50, 90, 99)
+		stats.P50Latency, stats.P90Latency, stats.P99Latency = ps[0], ps[1], ps[2]
+		stats.MaxLatency = stats.latency.Max()
+		report.Count += stats.Count
+		report.Failed += stats.Failed
+		report.Calls = append(report.Calls, stats)
+	}
+	if seconds > 0 {
+		report.QPS = float64(report.Count) / seconds
+	}
+	return report
+}
+
+func (r *Report) print() {
+	log.WithFields(logrus.Fields{
+		"duration": r.Duration.Seconds(),
+		"count":    r.Count,
+		"failed":   r.Failed,
+		"qps":      r.QPS,
+	}).Info("query report")
+	for _, c := range r.Calls {
+		entry := log.WithFields(logrus.Fields{
+			"call":        c.Name,
+			"count":       c.Count,
+			"failed":      c.Failed,
+			"qps":         c.QPS,
+			"avg_latency": c.AvgLatency.String(),
+			"p50_latency": c.P50Latency.String(),
+			"p90_latency": c.P90Latency.String(),
+			"p99_latency": c.P99Latency.String(),
+			"max_latency": c.MaxLatency.String(),
+		})
+		if c.LastError != "" {
+			entry = entry.WithField("last_error", c.LastError)
+		}
+		entry.Info("query call report")
+	}
+}
+
+func formatMix(mix map[string]int) string {
+	items := make([]string, 0, len(mix))
+	for _, call := range AllCalls {
+		if weight, ok := mix[call]; ok {
+			items = append(items, fmt.Sprintf("%s=%d", call, weight))
+		}
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	all := make(map[string]int, len(AllCalls))
+	for _, call := range AllCalls {
+		all[call] = 1
+	}
+	tests := []struct {
+		name    string
+		list    string
+		want    map[string]int
+		wantErr bool
+	}{
+		{"empty is every call", "", all, false},
+		{"weights", "blocks=3,receipt=1", map[string]int{Blocks: 3, Receipt: 1}, false},
+		{"default weight", "chain_meta", map[string]int{ChainMeta: 1}, false},
+		{"spaces", " balance=2 , service ", map[string]int{Balance: 2, Service: 1}, false},
+		{"zero weight", "blocks=0", map[string]int{Blocks: 0}, false},
+		{"negative weight", "blocks=-1", nil, true},
+		{"bad weight", "blocks=x", nil, true},
+		{"unknown call", "blocks=1,proposal=1", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMix(tt.list)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/meshplus/bitxhub-kit/types"
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	seedBlocks     = 100  // latest blocks scanned for hashes and accounts
+	seedMax        = 1000 // bound of every seeded list
+	seedCandidates = 100  // accounts tried as appchain IDs
+)
+
+// seed holds what the queries read, scanned from the latest blocks before the run
+type seed struct {
+	height    uint64
+	blocks    []string // block hashes
+	txs       []string // tx hashes
+	accounts  []string
+	appchains []string
+	services  []string // chainID:serviceID
+}
+
+func newSeed(client rpcx.Client) (*seed, error) {
+	meta, err := client.GetChainMeta()
+	if err != nil {
+		return nil, err
+	}
+	if meta.Height == 0 {
+		return nil, fmt.Errorf("chain has no block")
+	}
+	s := &seed{height: meta.Height}
+	start := uint64(1)
+	if s.height > seedBlocks {
+		start = s.height - seedBlocks + 1
+	}
+	res, err := client.GetBlocks(start, s.height, true)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, block := range res.Blocks {
+		if len(s.blocks) < seedMax && block.BlockHash != nil {
+			s.blocks = append(s.blocks, block.BlockHash.String())
+		}
+		if block.Transactions == nil {
+			continue
+		}
+		for _, tx := range block.Transactions.Transactions {
+			if len(s.txs) < seedMax {
+				s.txs = append(s.txs, tx.GetHash().String())
+			}
+			from := tx.GetFrom()
+			if from == nil || seen[from.String()] || len(s.accounts) >= seedMax {
+				continue
+			}
+			seen[from.String()] = true
+			s.accounts = append(s.accounts, from.String())
+		}
+	}
+	_, admin, err := repo.Node1Priv()
+	if err != nil {
+		return nil, err
+	}
+	if !seen[admin.String()] {
+		s.accounts = append(s.accounts, admin.String())
+	}
+
+	// premo registers the appchains with the addresses of their admins as IDs
+	for i, account := range s.accounts {
+		if i >= seedCandidates {
+			break
+		}
+		if res, err := view(client, constant.AppchainMgrContractAddr.Address(), "GetAppchain", account); err != nil || res.Status != pb.Receipt_SUCCESS {
+			continue
+		}
+		s.appchains = append(s.appchains, account)
+		id := service.NewBuilder("").Default(account).ChainServiceID()
+		if res, err := view(client, constant.ServiceMgrContractAddr.Address(), "GetServiceInfo", id); err == nil && res.Status == pb.Receipt_SUCCESS {
+			s.services = append(s.services, id)
+		}
+	}
+	log.WithFields(logrus.Fields{
+		"height":    s.height,
+		"blocks":    len(s.blocks),
+		"txs":       len(s.txs),
+		"accounts":  len(s.accounts),
+		"appchains": len(s.appchains),
+		"services":  len(s.services),
+	}).Info("query workload seeded")
+	return s, nil
+}
+
+// supports reports whether the seed has what call reads
+func (s *seed) supports(call string) bool {
+	switch call {
+	case BlockByHash:
+		return len(s.blocks) != 0
+	case Receipt, Transaction:
+		return len(s.txs) != 0
+	case Appchain:
+		return len(s.appchains) != 0
+	case Service:
+		return len(s.services) != 0
+	default:
+		return true
+	}
+}
+
+// needs describes what call reads
+func (s *seed) needs(call string) string {
+	switch call {
+	case BlockByHash:
+		return "block hash"
+	case Receipt, Transaction:
+		return "tx"
+	case Appchain:
+		return "appchain"
+	case Service:
+		return "service"
+	default:
+		return call
+	}
+}
+
+// call sends one query picked at random from the seed
+func (s *seed) call(client rpcx.Client, call string, r *rand.Rand, blocksRange int) error {
+	var err error
+	switch call {
+	case BlockByHeight:
+		height := uint64(r.Int63n(int64(s.height))) + 1
+		_, err = client.GetBlock(strconv.FormatUint(height, 10), pb.GetBlockRequest_HEIGHT, false)
+	case BlockByHash:
+		_, err = client.GetBlock(s.blocks[r.Intn(len(s.blocks))], pb.GetBlockRequest_HASH, false)
+	case Blocks:
+		start, end := uint64(1), s.height
+		if s.height > uint64(blocksRange) {
+			start = uint64(r.Int63n(int64(s.height-uint64(blocksRange)))) + 1
+			end = start + uint64(blocksRange) - 1
+		}
+		_, err = client.GetBlocks(start, end, false)
+	case Receipt:
+		_, err = client.GetReceipt(s.txs[r.Intn(len(s.txs))])
+	case Transaction:
+		_, err = client.GetTransaction(s.txs[r.Intn(len(s.txs))])
+	case Balance:
+		_, err = client.GetAccountBalance(s.accounts[r.Intn(len(s.accounts))])
+	case ChainMeta:
+		_, err = client.GetChainMeta()
+	case Appchain:
+		err = checkView(view(client, constant.AppchainMgrContractAddr.Address(), "GetAppchain", s.appchains[r.Intn(len(s.appchains))]))
+	case Service:
+		err = checkView(view(client, constant.ServiceMgrContractAddr.Address(), "GetServiceInfo", s.services[r.Intn(len(s.services))]))
+	default:
+		err = fmt.Errorf("unsupported query %s", call)
+	}
+	return err
+}
+
+// view calls a read-only BVM method without sending a tx
+func view(client rpcx.Client, address *types.Address, method, id string) (*pb.Receipt, error) {
+	tx, err := client.GenerateContractTx(pb.TransactionData_BVM, address, method, rpcx.String(id))
+	if err != nil {
+		return nil, err
+	}
+	return client.SendView(tx)
+}
+
+func checkView(res *pb.Receipt, err error) error {
+	if err != nil {
+		return err
+	}
+	if res.Status != pb.Receipt_SUCCESS {
+		return fmt.Errorf("%s", string(res.Ret))
+	}
+	return nil
+}
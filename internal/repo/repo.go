@@ -3,6 +3,7 @@ package repo
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
@@ -66,6 +67,11 @@ func Node4Path() (string, error) {
 	return filePath("node4.json")
 }
 
+// AdminsPath return admins.json path, the admin key files and weights used for voting
+func AdminsPath() (string, error) {
+	return filePath("admins.json")
+}
+
 // getPrivByPath return privateKey and address by path
 func getPrivByPath(path string) (crypto.PrivateKey, *types.Address, error) {
 	pk, err := asym.RestorePrivateKey(path, KeyPassword)
@@ -79,6 +85,19 @@ func getPrivByPath(path string) (crypto.PrivateKey, *types.Address, error) {
 	return pk, from, nil
 }
 
+// PrivByPath return privateKey and address by the key file path, relative paths are
+// resolved against the repo root
+func PrivByPath(path string) (crypto.PrivateKey, *types.Address, error) {
+	if !filepath.IsAbs(path) {
+		p, err := filePath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		path = p
+	}
+	return getPrivByPath(path)
+}
+
 // Node1Priv return node1's privateKey and address
 func Node1Priv() (crypto.PrivateKey, *types.Address, error) {
 	path, err := Node1Path()
@@ -115,9 +134,67 @@ func Node4Priv() (crypto.PrivateKey, *types.Address, error) {
 	return getPrivByPath(path)
 }
 
+// keyAlgorithms are the algorithms of generated accounts by their names, the
+// names of bitxhub-kit are accepted as well. ED25519 is left out, bitxhub-kit can not
+// generate its keys.
+var keyAlgorithms = map[string]crypto.KeyType{
+	"secp256k1":  crypto.Secp256k1,
+	"sm2":        crypto.SM2,
+	"ecdsa-p256": crypto.ECDSA_P256,
+	"ecdsa_p256": crypto.ECDSA_P256,
+}
+
+// keyTypeNames are the canonical names of the algorithms in keyAlgorithms
+var keyTypeNames = map[crypto.KeyType]string{
+	crypto.Secp256k1:  "Secp256k1",
+	crypto.SM2:        "SM2",
+	crypto.ECDSA_P256: "ECDSA-P256",
+}
+
+var keyType crypto.KeyType = crypto.Secp256k1
+
+// ParseKeyType returns the algorithm called name, empty is Secp256k1. It fails if this
+// build can not generate keys of the algorithm.
+func ParseKeyType(name string) (crypto.KeyType, error) {
+	if name == "" {
+		return crypto.Secp256k1, nil
+	}
+	typ, ok := keyAlgorithms[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported crypto algorithm %s, want Secp256k1, SM2 or ECDSA-P256", name)
+	}
+	if _, err := asym.GenerateKeyPair(typ); err != nil {
+		return 0, fmt.Errorf("crypto algorithm %s: %w", name, err)
+	}
+	return typ, nil
+}
+
+// SetKeyType sets the algorithm of the keys generated by KeyPriv, empty is Secp256k1
+func SetKeyType(name string) error {
+	typ, err := ParseKeyType(name)
+	if err != nil {
+		return err
+	}
+	keyType = typ
+	return nil
+}
+
+// KeyType returns the algorithm of the keys generated by KeyPriv
+func KeyType() crypto.KeyType {
+	return keyType
+}
+
+// KeyTypeName returns the name of the algorithm of the keys generated by KeyPriv
+func KeyTypeName() string {
+	if name, ok := keyTypeNames[keyType]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 // KeyPriv return privateKey and address
 func KeyPriv() (crypto.PrivateKey, *types.Address, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(keyType)
 	if err != nil {
 		return nil, nil, err
 	}
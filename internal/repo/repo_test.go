@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyTypeName(t *testing.T) {
+	defer func() {
+		require.NoError(t, SetKeyType(""))
+	}()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "Secp256k1"},
+		{"secp256k1", "Secp256k1"},
+		{"ecdsa-p256", "ECDSA-P256"},
+		{"ECDSA_P256", "ECDSA-P256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want+"/"+tt.name, func(t *testing.T) {
+			require.NoError(t, SetKeyType(tt.name))
+			require.Equal(t, tt.want, KeyTypeName())
+		})
+	}
+}
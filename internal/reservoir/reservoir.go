@@ -0,0 +1,104 @@
+package reservoir
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MaxSamples bounds the number of latencies kept for percentiles
+const MaxSamples = 100000
+
+// Latencies keeps a uniform sample of latencies with bounded size, the mean and the
+// max are over all added latencies
+type Latencies struct {
+	lock    sync.Mutex
+	samples []time.Duration
+	seen    uint64
+	sum     time.Duration
+	max     time.Duration
+	random  *rand.Rand
+}
+
+// New returns empty latencies
+func New() *Latencies {
+	return &Latencies{
+		samples: make([]time.Duration, 0, 1024),
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Add adds a latency
+func (l *Latencies) Add(latency time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.seen++
+	l.sum += latency
+	if latency > l.max {
+		l.max = latency
+	}
+	if len(l.samples) < MaxSamples {
+		l.samples = append(l.samples, latency)
+		return
+	}
+	// reservoir sampling keeps every latency with the same probability
+	if idx := l.random.Int63n(int64(l.seen)); idx < MaxSamples {
+		l.samples[idx] = latency
+	}
+}
+
+// Count returns the number of added latencies
+func (l *Latencies) Count() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.seen
+}
+
+// Mean returns the average of all added latencies
+func (l *Latencies) Mean() time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.seen == 0 {
+		return 0
+	}
+	return l.sum / time.Duration(l.seen)
+}
+
+// Max returns the largest added latency
+func (l *Latencies) Max() time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.max
+}
+
+// Percentiles returns the latencies at the given percentiles (0-100)
+func (l *Latencies) Percentiles(ps ...float64) []time.Duration {
+	l.lock.Lock()
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	l.lock.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	ret := make([]time.Duration, len(ps))
+	for i, p := range ps {
+		ret[i] = Percentile(sorted, p)
+	}
+	return ret
+}
+
+// Percentile returns the nearest-rank latency at p (0-100) of the sorted latencies
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted))*p/100+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
@@ -12,14 +12,18 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/appchain"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 )
 
 var (
 	nonce1        uint64 = 0
-	nonce2        uint64 = 0
-	nonce3        uint64 = 0
-	defaultRemote        = "localhost:60011"
+	admins        *admin.Set
+	profile       *appchain.Profile
+	services      = service.NewBuilder("")
+	defaultRemote = "localhost:60011"
 )
 
 type RegisterResult struct {
@@ -47,19 +51,7 @@ func initializeAdminNonce() error {
 	if err != nil {
 		return err
 	}
-	_, node2, err := repo.Node2Priv()
-	if err != nil {
-		return err
-	}
-	nonce2, err = client.GetPendingNonceByAccount(node2.String())
-	if err != nil {
-		return err
-	}
-	_, node3, err := repo.Node3Priv()
-	if err != nil {
-		return err
-	}
-	nonce3, err = client.GetPendingNonceByAccount(node3.String())
+	admins, err = admin.Load()
 	if err != nil {
 		return err
 	}
@@ -95,15 +87,23 @@ func RegisterAppchain(pk crypto.PrivateKey) error {
 	if err != nil {
 		return err
 	}
+	trustRoot, err := profile.TrustRootData()
+	if err != nil {
+		return err
+	}
+	rule, _, err := profile.Rule(client, nil)
+	if err != nil {
+		return err
+	}
 	args := []*pb.Arg{
-		rpcx.String(from.String()),   //chainID
-		rpcx.String(from.String()),   //chainName
-		rpcx.Bytes(bytes),            //pubKey
-		rpcx.String("Fabric V1.4.3"), //chainType
-		rpcx.Bytes([]byte("")),       //trustRoot
-		rpcx.String("{\"channel_id\":\"mychannel\",\"chaincode_id\":\"broker\",\"broker_version\":\"1\"}"), //broker
+		rpcx.String(from.String()),        //chainID
+		rpcx.String(from.String()),        //chainName
+		rpcx.Bytes(bytes),                 //pubKey
+		rpcx.String(profile.ChainType),    //chainType
+		rpcx.Bytes(trustRoot),             //trustRoot
+		rpcx.String(profile.Broker),       //broker
 		rpcx.String("desc"),               //desc
-		rpcx.String(HappyRuleAddr),        //masterRuleAddr
+		rpcx.String(rule),                 //masterRuleAddr
 		rpcx.String("https://github.com"), //masterRuleUrl
 		rpcx.String(from.String()),        //adminAddrs
 		rpcx.String("reason"),             //reason
@@ -173,8 +173,8 @@ func RegisterServer(pk crypto.PrivateKey) error {
 func MockIBTP(from, to *types.Address, index uint64) *pb.IBTP {
 	proofHash := sha256.Sum256([]byte("mock ibtp"))
 	return &pb.IBTP{
-		From:          "1356:" + from.String() + ":" + from.String(),
-		To:            "1356:" + to.String() + ":" + to.String(),
+		From:          services.ID(from.String(), from.String()).String(),
+		To:            services.ID(to.String(), to.String()).String(),
 		Index:         index,
 		Type:          pb.IBTP_INTERCHAIN,
 		TimeoutHeight: 10,
@@ -197,97 +197,32 @@ func MockContent(funcName string, args [][]byte) []byte {
 }
 
 func VotePass(id string) error {
-	return Vote(id, "approve")
+	return Vote(id, admin.Approve)
 }
 
-//Vote `vote` proposal by id and info with four admin
+// Vote `vote` proposal by id and info with the admin set
 func Vote(id, info string) error {
-	key1, _, err := repo.Node1Priv()
+	key1, node1, err := repo.Node1Priv()
 	if err != nil {
 		return err
 	}
-	nonce := atomic.AddUint64(&nonce1, 1)
-	res, err := vote(key1, nonce-1, rpcx.String(id), rpcx.String(info), rpcx.String("Vote"))
-	if err != nil {
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-	key2, _, err := repo.Node2Priv()
-	if err != nil {
-		return err
-	}
-	nonce = atomic.AddUint64(&nonce2, 1)
-	res, err = vote(key2, nonce-1, rpcx.String(id), rpcx.String(info), rpcx.String("Vote"))
-	if err != nil {
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-	key3, _, err := repo.Node3Priv()
-	if err != nil {
-		return err
-	}
-	nonce = atomic.AddUint64(&nonce3, 1)
-	res, err = vote(key3, nonce-1, rpcx.String(id), rpcx.String(info), rpcx.String("Vote"))
-	if err != nil {
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-	return nil
-}
-
-// vote `vote` proposal
-func vote(key crypto.PrivateKey, nonce uint64, args ...*pb.Arg) (*pb.Receipt, error) {
 	client, err := rpcx.New(
 		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: defaultRemote}),
-		rpcx.WithPrivateKey(key),
+		rpcx.WithPrivateKey(key1),
 	)
 	if err != nil {
-		return nil, err
-	}
-	address, err := key.PublicKey().Address()
-	if err != nil {
-		return nil, err
-	}
-	invokePayload := &pb.InvokePayload{
-		Method: "Vote",
-		Args:   args,
-	}
-	payload, err := invokePayload.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	data := &pb.TransactionData{
-		Type:    pb.TransactionData_INVOKE,
-		VmType:  pb.TransactionData_BVM,
-		Payload: payload,
-	}
-	payload, err = data.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	tx := &pb.BxhTransaction{
-		From:      address,
-		To:        constant.GovernanceContractAddr.Address(),
-		Timestamp: time.Now().UnixNano(),
-		Payload:   payload,
-	}
-	res, err := client.SendTransactionWithReceipt(tx, &rpcx.TransactOpts{
-		From:  address.String(),
-		Nonce: nonce,
-	})
-	if err != nil {
-		return nil, err
+		return err
 	}
-	if res.Status == pb.Receipt_FAILED {
-		return nil, fmt.Errorf(string(res.Ret))
+	defer client.Stop()
+	voter := admin.NewVoter(client, admins, "Vote")
+	// node1 also funds accounts, share its nonce with TransferFromAdmin
+	voter.Nonce = func(a *admin.Admin) (uint64, error) {
+		if a.Address.String() == node1.String() {
+			return atomic.AddUint64(&nonce1, 1) - 1, nil
+		}
+		return a.NextNonce(client)
 	}
-	return res, nil
+	return voter.Vote(id, info)
 }
 
 func TransferFromAdmin(remote, address, amount string) error {
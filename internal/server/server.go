@@ -14,14 +14,15 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/appchain"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	NormalKey     = "key_for_normal"
-	NormalValue   = "value_for_normal"
-	HappyRuleAddr = "0x00000000000000000000000000000000000000a2"
+	NormalKey   = "key_for_normal"
+	NormalValue = "value_for_normal"
 )
 
 type Server struct {
@@ -41,8 +42,10 @@ type Grpc struct {
 	index   uint64
 }
 
-func NewServer(remote string, port, poolSize int) (*Server, error) {
+func NewServer(remote string, port, poolSize int, chain *appchain.Profile, bxhID string) (*Server, error) {
 	defaultRemote = remote
+	profile = chain
+	services = service.NewBuilder(bxhID)
 	err := initializeAdminNonce()
 	if err != nil {
 		return nil, err
@@ -124,6 +127,7 @@ func (server *Server) Start() {
 		v1.GET("/setData", server.setData)
 		v1.GET("/getData", server.getData)
 	}
+	server.log.WithField("crypto", repo.KeyTypeName()).Infof("server listens on :%d", server.port)
 	err := server.router.Run(fmt.Sprintf(":%d", server.port))
 	if err != nil {
 		server.log.Error(err)
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultBxhID is the chain ID of a bitxhub started from the default genesis
+	DefaultBxhID = "1356"
+	// DefaultServiceID is the service registered for every appchain prepared by premo
+	DefaultServiceID = "mychannel&transfer"
+
+	separator = ":"
+)
+
+// ID is a full service ID, bxhID:chainID:serviceID
+type ID struct {
+	BxhID     string
+	ChainID   string
+	ServiceID string
+}
+
+func (id ID) String() string {
+	return id.BxhID + separator + id.ChainID + separator + id.ServiceID
+}
+
+// ChainServiceID returns the chainID:serviceID part, the ID of the service in its own relay chain
+func (id ID) ChainServiceID() string {
+	return id.ChainID + separator + id.ServiceID
+}
+
+// ParseID parses a full service ID
+func ParseID(s string) (ID, error) {
+	parts := strings.Split(s, separator)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return ID{}, fmt.Errorf("invalid full service id %s, want bxhID:chainID:serviceID", s)
+	}
+	return ID{BxhID: parts[0], ChainID: parts[1], ServiceID: parts[2]}, nil
+}
+
+// Builder builds the full service IDs under one relay chain
+type Builder struct {
+	BxhID string
+}
+
+// NewBuilder returns a builder for the relay chain bxhID, an empty bxhID is the default one
+func NewBuilder(bxhID string) Builder {
+	if bxhID == "" {
+		bxhID = DefaultBxhID
+	}
+	return Builder{BxhID: bxhID}
+}
+
+// ID returns the full ID of serviceID on chainID
+func (b Builder) ID(chainID, serviceID string) ID {
+	return ID{BxhID: b.BxhID, ChainID: chainID, ServiceID: serviceID}
+}
+
+// Default returns the full ID of the default service on chainID
+func (b Builder) Default(chainID string) ID {
+	return b.ID(chainID, DefaultServiceID)
+}
@@ -0,0 +1,57 @@
+package subscribe
+
+import (
+	"strings"
+
+	"github.com/meshplus/bitxhub-kit/crypto"
+	"github.com/meshplus/bitxhub-model/constant"
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/repo"
+)
+
+const auditReason = "premo subscribe"
+
+// auditNode is the nvp node whose key opens the audit subscriptions
+type auditNode struct {
+	key     crypto.PrivateKey
+	account string
+}
+
+// registerAuditNode registers an nvp node permitted to audit the appchains, the
+// subscriptions of AUDIT_NODE are only served to such nodes
+func registerAuditNode(client rpcx.Client, admins *admin.Set, permit []string) (*auditNode, error) {
+	pk, from, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	node := &auditNode{key: pk, account: from.String()}
+	err = invokeGovernance(client, admins, "RegisterNode",
+		rpcx.String(node.account),              //nodeAccount
+		rpcx.String("nvpNode"),                 //nodeType
+		rpcx.String(""),                        //nodePid
+		rpcx.Uint64(0),                         //nodeVpId
+		rpcx.String(node.account),              //nodeName
+		rpcx.String(strings.Join(permit, ",")), //permitStr
+		rpcx.String(auditReason),
+	)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("audit node %s permitted to audit %s", node.account, strings.Join(permit, ","))
+	return node, nil
+}
+
+// logout logs the audit node out at the end of the run
+func (n *auditNode) logout(client rpcx.Client, admins *admin.Set) {
+	if err := invokeGovernance(client, admins, "LogoutNode", rpcx.String(n.account), rpcx.String(auditReason)); err != nil {
+		log.WithField("error", err).Warnf("logout audit node %s", n.account)
+	}
+}
+
+// invokeGovernance calls the node manager as the governor and approves the proposal
+func invokeGovernance(client rpcx.Client, admins *admin.Set, method string, args ...*pb.Arg) error {
+	voter := admin.NewVoter(client, admins, auditReason)
+	return voter.Govern(constant.NodeManagerContractAddr.Address(), method, args...)
+}
@@ -0,0 +1,77 @@
+package subscribe
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cpuMetric is the prometheus counter of the cpu seconds spent by a node process
+const cpuMetric = "process_cpu_seconds_total"
+
+const scrapeTimeout = 5 * time.Second
+
+// NodeCPU is the cpu a node spent in a step, in cores
+type NodeCPU struct {
+	Node  string  `json:"node"`
+	Cores float64 `json:"cores"`
+}
+
+// scrapeCPU reads the cpu seconds of every metrics endpoint, endpoints failing are left out
+func scrapeCPU(addrs []string) map[string]float64 {
+	seconds := make(map[string]float64, len(addrs))
+	client := &http.Client{Timeout: scrapeTimeout}
+	for _, addr := range addrs {
+		value, err := scrape(client, addr)
+		if err != nil {
+			log.WithField("error", err).Warnf("scrape cpu of %s", addr)
+			continue
+		}
+		seconds[addr] = value
+	}
+	return seconds
+}
+
+func scrape(client *http.Client, addr string) (float64, error) {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	resp, err := client.Get(addr)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("status %s", resp.Status)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != cpuMetric {
+			continue
+		}
+		return strconv.ParseFloat(fields[1], 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no %s metric", cpuMetric)
+}
+
+// cpuUsage returns the cores every node spent between the two scrapes
+func cpuUsage(addrs []string, before, after map[string]float64, elapsed time.Duration) []*NodeCPU {
+	var usage []*NodeCPU
+	for _, addr := range addrs {
+		b, ok1 := before[addr]
+		a, ok2 := after[addr]
+		if !ok1 || !ok2 || elapsed <= 0 {
+			continue
+		}
+		usage = append(usage, &NodeCPU{Node: addr, Cores: (a - b) / elapsed.Seconds()})
+	}
+	return usage
+}
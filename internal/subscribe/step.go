@@ -0,0 +1,409 @@
+package subscribe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/clock"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/reservoir"
+	"github.com/sirupsen/logrus"
+)
+
+// event is what a subscriber received, keyed so the same event of every subscriber of
+// a type matches
+type event struct {
+	key       string
+	height    uint64
+	timestamp int64 // block timestamp, 0 if the event carries none
+}
+
+// eventOf extracts the event of a subscription message
+func eventOf(typ string, data interface{}) (*event, error) {
+	switch msg := data.(type) {
+	case *pb.Block:
+		return &event{key: strconv.FormatUint(msg.BlockHeader.Number, 10), height: msg.BlockHeader.Number, timestamp: msg.BlockHeader.Timestamp}, nil
+	case *pb.BlockHeader:
+		return &event{key: strconv.FormatUint(msg.Number, 10), height: msg.Number, timestamp: msg.Timestamp}, nil
+	case *pb.InterchainTxWrappers:
+		if len(msg.InterchainTxWrappers) == 0 {
+			return nil, fmt.Errorf("empty interchain tx wrappers")
+		}
+		height := msg.InterchainTxWrappers[0].Height
+		return &event{key: strconv.FormatUint(height, 10), height: height}, nil
+	case *pb.AuditTxInfo:
+		return &event{key: msg.Tx.GetHash().String(), height: msg.BlockHeight}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s message %T", typ, data)
+	}
+}
+
+// union is every event of a type first seen by any subscriber within the step
+type union struct {
+	lock   sync.Mutex
+	events map[string]*event
+}
+
+func (u *union) add(e *event) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if _, ok := u.events[e.key]; ok || len(u.events) >= MaxTrackedEvents {
+		return
+	}
+	u.events[e.key] = e
+}
+
+func (u *union) has(key string) bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	_, ok := u.events[key]
+	return ok
+}
+
+// subscriber is one stream of a step
+type subscriber struct {
+	index    int
+	typ      string
+	addr     string
+	client   rpcx.Client
+	arrivals map[string]int64
+	dups     int64
+	closed   bool // the stream closed before the step ended
+	err      error
+}
+
+// step keeps n subscribers open for the duration of the run
+type step struct {
+	f           *Fanout
+	subscribers []*subscriber
+	unions      map[string]*union
+
+	lock     sync.RWMutex
+	counting bool // events first seen now join the union
+}
+
+func newStep(f *Fanout, n int) *step {
+	s := &step{f: f, unions: make(map[string]*union)}
+	for i := 0; i < n; i++ {
+		typ := f.config.Types[i%len(f.config.Types)]
+		s.subscribers = append(s.subscribers, &subscriber{
+			index:    i,
+			typ:      typ,
+			addr:     f.config.BitxhubAddr[i%len(f.config.BitxhubAddr)],
+			arrivals: make(map[string]int64),
+		})
+		if _, ok := s.unions[typ]; !ok {
+			s.unions[typ] = &union{events: make(map[string]*event)}
+		}
+	}
+	return s
+}
+
+func (s *step) run(ctx context.Context) (*StepStats, error) {
+	n := len(s.subscribers)
+	log.Infof("opening %d subscribers", n)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	opened := make(chan struct{}, n)
+	for _, sub := range s.subscribers {
+		wg.Add(1)
+		go func(sub *subscriber) {
+			defer wg.Done()
+			s.subscribe(ctx, sub, opened)
+		}(sub)
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case <-opened:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, nil
+		}
+	}
+
+	before := scrapeCPU(s.f.config.MetricsAddr)
+	start := time.Now()
+	s.setCounting(true)
+	select {
+	case <-time.After(time.Duration(s.f.config.Duration) * time.Second):
+	case <-ctx.Done():
+	}
+	s.setCounting(false)
+	end := time.Now()
+	after := scrapeCPU(s.f.config.MetricsAddr)
+	select {
+	case <-time.After(Grace):
+	case <-ctx.Done():
+	}
+	cancel()
+	wg.Wait()
+	for _, sub := range s.subscribers {
+		if sub.client != nil {
+			_ = sub.client.Stop()
+		}
+	}
+	return s.stats(start, end, before, after), nil
+}
+
+func (s *step) setCounting(counting bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.counting = counting
+}
+
+func (s *step) isCounting() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.counting
+}
+
+// subscribe opens the stream of sub and records every event until ctx is done
+func (s *step) subscribe(ctx context.Context, sub *subscriber, opened chan<- struct{}) {
+	ch, err := s.open(ctx, sub)
+	opened <- struct{}{}
+	if err != nil {
+		sub.err = err
+		log.WithFields(logrus.Fields{"subscriber": sub.index, "type": sub.typ, "error": err}).Warn("subscribe")
+		return
+	}
+	defer func() {
+		if ctx.Err() == nil {
+			sub.closed = true
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data, ok := <-ch:
+			if !ok {
+				return
+			}
+			now := time.Now().UnixNano()
+			e, err := eventOf(sub.typ, data)
+			if err != nil {
+				log.WithField("error", err).Debug("subscription message")
+				continue
+			}
+			if _, ok := sub.arrivals[e.key]; ok {
+				sub.dups++
+				continue
+			}
+			if e.timestamp != 0 {
+				s.f.times.add(e.height, e.timestamp)
+			}
+			// after the step only the events others received within it are still recorded
+			if s.isCounting() {
+				s.unions[sub.typ].add(e)
+			} else if !s.unions[sub.typ].has(e.key) {
+				continue
+			}
+			sub.arrivals[e.key] = now
+		}
+	}
+}
+
+func (s *step) open(ctx context.Context, sub *subscriber) (<-chan interface{}, error) {
+	pk, _, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	if sub.typ == AuditNode {
+		pk = s.f.audit.key
+	}
+	sub.client, err = rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: sub.addr}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		return nil, err
+	}
+	switch sub.typ {
+	case AuditNode:
+		meta, err := sub.client.GetChainMeta()
+		if err != nil {
+			return nil, err
+		}
+		return sub.client.SubscribeAudit(ctx, pb.AuditSubscriptionRequest_AUDIT_NODE, meta.Height+1, nil)
+	case InterchainTxWrapper, UnionInterchainTxWrapper:
+		return sub.client.Subscribe(ctx, requestTypes[sub.typ], []byte(s.f.config.Extra))
+	default:
+		return sub.client.Subscribe(ctx, requestTypes[sub.typ], nil)
+	}
+}
+
+// StepStats is the result of one subscriber count. An event is dropped by a subscriber
+// if another subscriber of its type received it within the step and it did not.
+type StepStats struct {
+	Subscribers int           `json:"subscribers"`
+	Opened      int           `json:"opened"`
+	Closed      int           `json:"closed"` // streams closed by the node before the step ended
+	Duration    time.Duration `json:"duration"`
+	Types       []*TypeStats  `json:"types"`
+	CPU         []*NodeCPU    `json:"cpu,omitempty"`
+}
+
+// TypeStats is the delivery of the subscribers of one type, latency is from the block
+// timestamp to the event received, corrected by the clock offset of the node. Subscribers
+// which failed to open are listed with their error but not counted in Delivered and Dropped.
+type TypeStats struct {
+	Type          string             `json:"type"`
+	Subscribers   int                `json:"subscribers"`
+	Events        int                `json:"events"`
+	Delivered     int64              `json:"delivered"`
+	Dropped       int64              `json:"dropped"`
+	Duplicated    int64              `json:"duplicated"`
+	AvgLatency    time.Duration      `json:"avg_latency"`
+	P50Latency    time.Duration      `json:"p50_latency"`
+	P99Latency    time.Duration      `json:"p99_latency"`
+	MaxLatency    time.Duration      `json:"max_latency"`
+	PerSubscriber []*SubscriberStats `json:"per_subscriber"`
+}
+
+// SubscriberStats is the delivery of one subscriber
+type SubscriberStats struct {
+	Index      int           `json:"index"`
+	Node       string        `json:"node"`
+	Error      string        `json:"error,omitempty"`
+	Delivered  int64         `json:"delivered"`
+	Dropped    int64         `json:"dropped"`
+	Duplicated int64         `json:"duplicated"`
+	P99Latency time.Duration `json:"p99_latency"`
+}
+
+func (s *step) stats(start, end time.Time, before, after map[string]float64) *StepStats {
+	stats := &StepStats{
+		Subscribers: len(s.subscribers),
+		Duration:    end.Sub(start),
+		CPU:         cpuUsage(s.f.config.MetricsAddr, before, after, end.Sub(start)),
+	}
+	for _, typ := range AllTypes {
+		u, ok := s.unions[typ]
+		if !ok {
+			continue
+		}
+		t := &TypeStats{Type: typ, Events: len(u.events)}
+		var all []time.Duration
+		var sum time.Duration
+		for _, sub := range s.subscribers {
+			if sub.typ != typ {
+				continue
+			}
+			t.Subscribers++
+			if sub.err == nil {
+				stats.Opened++
+			}
+			if sub.closed {
+				stats.Closed++
+			}
+			ss := &SubscriberStats{Index: sub.index, Node: sub.addr, Duplicated: sub.dups}
+			// a subscriber which failed to open received nothing, it would count every event as dropped
+			if sub.err != nil {
+				ss.Error = sub.err.Error()
+				t.PerSubscriber = append(t.PerSubscriber, ss)
+				continue
+			}
+			offset := s.f.offset(sub.addr)
+			var latencies []time.Duration
+			for key, e := range u.events {
+				arrival, ok := sub.arrivals[key]
+				if !ok {
+					ss.Dropped++
+					continue
+				}
+				ss.Delivered++
+				timestamp := e.timestamp
+				if timestamp == 0 {
+					if timestamp, ok = s.f.times.get(e.height); !ok {
+						continue
+					}
+				}
+				latency := time.Duration(arrival-timestamp) - offset
+				if latency < 0 {
+					latency = 0
+				}
+				latencies = append(latencies, latency)
+				sum += latency
+			}
+			sortDurations(latencies)
+			ss.P99Latency = reservoir.Percentile(latencies, 99)
+			all = append(all, latencies...)
+			t.Delivered += ss.Delivered
+			t.Dropped += ss.Dropped
+			t.Duplicated += ss.Duplicated
+			t.PerSubscriber = append(t.PerSubscriber, ss)
+		}
+		if len(all) != 0 {
+			sortDurations(all)
+			t.AvgLatency = sum / time.Duration(len(all))
+			t.P50Latency = reservoir.Percentile(all, 50)
+			t.P99Latency = reservoir.Percentile(all, 99)
+			t.MaxLatency = all[len(all)-1]
+		}
+		stats.Types = append(stats.Types, t)
+	}
+	return stats
+}
+
+func (s *StepStats) print() {
+	log.WithFields(logrus.Fields{
+		"subscribers": s.Subscribers,
+		"opened":      s.Opened,
+		"closed":      s.Closed,
+		"duration":    s.Duration.Seconds(),
+	}).Info("subscription step report")
+	for _, t := range s.Types {
+		var worst *SubscriberStats
+		for _, ss := range t.PerSubscriber {
+			if ss.Error != "" {
+				continue
+			}
+			if worst == nil || ss.P99Latency > worst.P99Latency {
+				worst = ss
+			}
+		}
+		fields := logrus.Fields{
+			"type":        t.Type,
+			"subscribers": t.Subscribers,
+			"events":      t.Events,
+			"delivered":   t.Delivered,
+			"dropped":     t.Dropped,
+			"duplicated":  t.Duplicated,
+			"avg_latency": t.AvgLatency.String(),
+			"p50_latency": t.P50Latency.String(),
+			"p99_latency": t.P99Latency.String(),
+			"max_latency": t.MaxLatency.String(),
+		}
+		if worst != nil {
+			fields["slowest_subscriber"] = worst.Index
+			fields["slowest_p99_latency"] = worst.P99Latency.String()
+		}
+		log.WithFields(fields).Info("subscription type report")
+	}
+	for _, c := range s.CPU {
+		log.WithFields(logrus.Fields{
+			"node":  c.Node,
+			"cores": c.Cores,
+		}).Info("node cpu report")
+	}
+}
+
+// Report is the summary of a finished fan-out workload, one step per subscriber count
+type Report struct {
+	Steps        []*StepStats    `json:"steps"`
+	ClockOffsets []*clock.Offset `json:"clock_offsets,omitempty"`
+}
+
+func sortDurations(ds []time.Duration) {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+}
@@ -0,0 +1,279 @@
+package subscribe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/clock"
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/sirupsen/logrus"
+)
+
+// subscription types of the fan-out workload
+const (
+	Block                    = "block"
+	BlockHeader              = "block_header"
+	InterchainTxWrapper      = "interchain_tx_wrapper"
+	UnionInterchainTxWrapper = "union_interchain_tx_wrapper"
+	AuditNode                = "audit_node"
+)
+
+// AllTypes are the subscription types in report order
+var AllTypes = []string{Block, BlockHeader, InterchainTxWrapper, UnionInterchainTxWrapper, AuditNode}
+
+var requestTypes = map[string]pb.SubscriptionRequest_Type{
+	Block:                    pb.SubscriptionRequest_BLOCK,
+	BlockHeader:              pb.SubscriptionRequest_BLOCK_HEADER,
+	InterchainTxWrapper:      pb.SubscriptionRequest_INTERCHAIN_TX_WRAPPER,
+	UnionInterchainTxWrapper: pb.SubscriptionRequest_UNION_INTERCHAIN_TX_WRAPPER,
+}
+
+const (
+	// Grace is how long the streams are still read after a step, so events sent at its
+	// end are not counted as dropped
+	Grace = 3 * time.Second
+	// MaxTrackedEvents bounds the events of one type tracked in a step, the first ones are tracked
+	MaxTrackedEvents = 100000
+)
+
+var log = logrus.New()
+
+// Config is the configuration of the subscription fan-out workload
+type Config struct {
+	Subscribers []int // subscriber count of every step
+	Types       []string
+	Duration    int // s uint, of every step
+	BitxhubAddr []string
+	Extra       string   // extra of the interchain tx wrapper subscriptions
+	AuditPermit []string // appchains the audit node is permitted to audit
+	MetricsAddr []string // prometheus endpoints of the nodes
+	ClockWarmup int
+}
+
+// ParseTypes parses a comma separated list of subscription types, empty for block and block_header
+func ParseTypes(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return []string{Block, BlockHeader}, nil
+	}
+	var types []string
+	for _, typ := range strings.Split(list, ",") {
+		typ = strings.TrimSpace(typ)
+		known := false
+		for _, t := range AllTypes {
+			known = known || t == typ
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported subscription type %s, supported: %s", typ, strings.Join(AllTypes, ", "))
+		}
+		types = append(types, typ)
+	}
+	return types, nil
+}
+
+// Fanout opens growing numbers of concurrent subscribers while another workload produces
+// traffic, and measures the delivery latency, the dropped and duplicated events of every
+// subscriber and the cpu of the nodes at every subscriber count
+type Fanout struct {
+	config *Config
+	client rpcx.Client
+	admins *admin.Set
+	audit  *auditNode
+	clock  *clock.Estimator
+	times  *blockTimes
+	ctx    context.Context
+	cancel context.CancelFunc
+	report *Report
+}
+
+// New checks the configuration and connects the client watching blocks
+func New(config *Config) (*Fanout, error) {
+	log.WithFields(logrus.Fields{
+		"subscribers": fmt.Sprint(config.Subscribers),
+		"types":       strings.Join(config.Types, ","),
+		"duration":    config.Duration,
+	}).Info("Premo subscription configuration")
+	for _, n := range config.Subscribers {
+		if n <= 0 {
+			return nil, fmt.Errorf("subscriber count should be positive: %d", n)
+		}
+	}
+	f := &Fanout{config: config, times: newBlockTimes()}
+	for _, typ := range config.Types {
+		if typ == AuditNode && len(config.AuditPermit) == 0 {
+			return nil, fmt.Errorf("audit_node subscribers need the appchains to audit")
+		}
+	}
+	pk, _, err := repo.KeyPriv()
+	if err != nil {
+		return nil, err
+	}
+	f.client, err = rpcx.New(
+		rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: config.BitxhubAddr[0]}),
+		rpcx.WithLogger(log),
+		rpcx.WithPrivateKey(pk),
+	)
+	if err != nil {
+		return nil, err
+	}
+	f.admins, err = admin.Load()
+	if err != nil {
+		return nil, err
+	}
+	if config.ClockWarmup > 0 {
+		f.clock = clock.NewEstimator(time.Duration(config.ClockWarmup) * time.Second)
+		log.Warnf("latencies are not measured in the first %ds while the clock offset to the nodes is estimated", config.ClockWarmup)
+	}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	return f, nil
+}
+
+// Start runs a step for every subscriber count
+func (f *Fanout) Start() error {
+	defer f.client.Stop()
+	if f.clock != nil {
+		f.estimateClock()
+	}
+	go f.times.run(f.ctx, f.client)
+
+	for _, typ := range f.config.Types {
+		if typ != AuditNode {
+			continue
+		}
+		created, err := manifest.Create("subscribe")
+		if err != nil {
+			return fmt.Errorf("create run manifest: %w", err)
+		}
+		defer created.Close()
+		audit, err := registerAuditNode(f.client, f.admins, f.config.AuditPermit)
+		if err != nil {
+			return fmt.Errorf("register audit node: %w", err)
+		}
+		f.audit = audit
+		// the node is logged out at the end of the run, unless the process is killed
+		if err := created.Record(manifest.Node, audit.account, nil); err != nil {
+			log.WithField("error", err).Warnf("record audit node %s", audit.account)
+		}
+		defer audit.logout(f.client, f.admins)
+		break
+	}
+
+	report := &Report{}
+	for _, n := range f.config.Subscribers {
+		s := newStep(f, n)
+		stats, err := s.run(f.ctx)
+		if err != nil {
+			return err
+		}
+		if f.ctx.Err() != nil {
+			return nil
+		}
+		stats.print()
+		report.Steps = append(report.Steps, stats)
+	}
+	if f.clock != nil {
+		report.ClockOffsets = f.clock.Offsets()
+	}
+	f.report = report
+	return nil
+}
+
+// Stop interrupts the running step
+func (f *Fanout) Stop() {
+	f.cancel()
+}
+
+// Report returns the summary of the finished workload, it is nil if the workload was interrupted
+func (f *Fanout) Report() *Report {
+	return f.report
+}
+
+// estimateClock warms up the clock offset of every node before the first step
+func (f *Fanout) estimateClock() {
+	var wg sync.WaitGroup
+	for _, addr := range f.config.BitxhubAddr {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			pk, _, err := repo.KeyPriv()
+			if err != nil {
+				log.WithField("error", err).Error("estimate clock")
+				return
+			}
+			client, err := rpcx.New(
+				rpcx.WithNodesInfo(&rpcx.NodeInfo{Addr: addr}),
+				rpcx.WithLogger(log),
+				rpcx.WithPrivateKey(pk),
+			)
+			if err != nil {
+				log.WithField("error", err).Error("estimate clock")
+				return
+			}
+			defer client.Stop()
+			if err := f.clock.Run(f.ctx, client, addr); err != nil {
+				log.WithField("error", err).Error("estimate clock")
+			}
+		}(addr)
+	}
+	wg.Wait()
+}
+
+// offset returns the clock offset of the node at addr, zero until its warm-up is over
+func (f *Fanout) offset(addr string) time.Duration {
+	if f.clock == nil {
+		return 0
+	}
+	offset, _ := f.clock.Offset(addr)
+	return offset
+}
+
+// blockTimes keeps the timestamp of every block seen during the run, events carrying
+// only a height take the timestamp of their block
+type blockTimes struct {
+	lock  sync.RWMutex
+	times map[uint64]int64
+}
+
+func newBlockTimes() *blockTimes {
+	return &blockTimes{times: make(map[uint64]int64)}
+}
+
+func (b *blockTimes) add(height uint64, timestamp int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.times[height] = timestamp
+}
+
+func (b *blockTimes) get(height uint64) (int64, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	timestamp, ok := b.times[height]
+	return timestamp, ok
+}
+
+// run follows the block headers until ctx is done
+func (b *blockTimes) run(ctx context.Context, client rpcx.Client) {
+	ch, err := client.Subscribe(ctx, pb.SubscriptionRequest_BLOCK_HEADER, nil)
+	if err != nil {
+		log.WithField("error", err).Error("subscribe block headers")
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data, ok := <-ch:
+			if !ok {
+				return
+			}
+			header := data.(*pb.BlockHeader)
+			b.add(header.Number, header.Timestamp)
+		}
+	}
+}
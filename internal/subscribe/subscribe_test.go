@@ -0,0 +1,32 @@
+package subscribe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"empty is blocks", "", []string{Block, BlockHeader}, false},
+		{"one", "audit_node", []string{AuditNode}, false},
+		{"order kept", "union_interchain_tx_wrapper, block", []string{UnionInterchainTxWrapper, Block}, false},
+		{"unknown", "block,event", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTypes(tt.list)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package tps
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxWindow is the most blocks GetTPS accepts in one call
+	MaxWindow = 2048
+
+	DefaultQuiet        = 5 * time.Second
+	DefaultDrainTimeout = 120 * time.Second
+
+	drainPollInterval = time.Second
+)
+
+var log = logrus.New()
+
+// Config is how the tps of a run is collected once the bees stop
+type Config struct {
+	Warmup         time.Duration // excluded from the start of the run by block timestamp
+	Cooldown       time.Duration // excluded from the end of the run by block timestamp
+	WarmupBlocks   uint64        // excluded from the start of the run by count
+	CooldownBlocks uint64        // excluded from the end of the run by count
+	Window         uint64        // blocks per GetTPS call, 0 for MaxWindow
+	Quiet          time.Duration // the chain is drained once its height stays the same that long, 0 for DefaultQuiet
+	DrainTimeout   time.Duration // bound of the drain wait, 0 for DefaultDrainTimeout
+}
+
+func (c *Config) window() uint64 {
+	if c.Window == 0 || c.Window > MaxWindow {
+		return MaxWindow
+	}
+	return c.Window
+}
+
+func (c *Config) quiet() time.Duration {
+	if c.Quiet == 0 {
+		return DefaultQuiet
+	}
+	return c.Quiet
+}
+
+func (c *Config) drainTimeout() time.Duration {
+	if c.DrainTimeout == 0 {
+		return DefaultDrainTimeout
+	}
+	return c.DrainTimeout
+}
+
+// Stats is the tps measured over the blocks of a run, window by window, the mean and the
+// stddev weight each window by its blocks
+type Stats struct {
+	From    uint64   `json:"from"` // height after the warm-up
+	To      uint64   `json:"to"`   // height before the cool-down
+	Windows []uint64 `json:"windows"`
+	Blocks  []uint64 `json:"blocks"` // blocks of each window, the weight of its tps
+	Mean    float64  `json:"mean"`
+	StdDev  float64  `json:"stddev"`
+}
+
+// Drain waits until done reports true or the chain height stays the same for the quiet
+// period, whichever comes first, done may be nil. It returns the height reached, also when
+// the timeout of config passes first.
+func Drain(client rpcx.Client, config *Config, done func() bool) (uint64, error) {
+	var (
+		height   uint64
+		changed  = time.Now()
+		deadline = time.Now().Add(config.drainTimeout())
+	)
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		meta, err := client.GetChainMeta()
+		if err != nil {
+			return height, err
+		}
+		if meta.Height != height {
+			height = meta.Height
+			changed = time.Now()
+		}
+		if (done != nil && done()) || time.Since(changed) >= config.quiet() {
+			return height, nil
+		}
+		if time.Now().After(deadline) {
+			return height, fmt.Errorf("chain is still growing at height %d after %s", height, config.drainTimeout())
+		}
+		<-ticker.C
+	}
+}
+
+// Measure excludes the warm-up and cool-down of config from the blocks begin to end and
+// averages the tps of the windows left, weighted by their blocks so a short last window
+// counts no more than its share of the run
+func Measure(client rpcx.Client, begin, end uint64, config *Config) (*Stats, error) {
+	from, to, err := trim(client, begin, end, config)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{From: from, To: to}
+	for lo := from; lo < to; lo += config.window() {
+		hi := lo + config.window()
+		if hi > to {
+			hi = to
+		}
+		tps, err := client.GetTPS(lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("the TPS from block %d to %d is %d", lo, hi, tps)
+		stats.Windows = append(stats.Windows, tps)
+		stats.Blocks = append(stats.Blocks, hi-lo)
+	}
+
+	if len(stats.Windows) == 0 {
+		return stats, nil
+	}
+	var sum, blocks float64
+	for i, tps := range stats.Windows {
+		sum += float64(tps) * float64(stats.Blocks[i])
+		blocks += float64(stats.Blocks[i])
+	}
+	stats.Mean = sum / blocks
+	var squares float64
+	for i, tps := range stats.Windows {
+		squares += float64(stats.Blocks[i]) * (float64(tps) - stats.Mean) * (float64(tps) - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(squares / blocks)
+	log.Infof("the total TPS from block %d to %d is %.2f, the stddev over %d windows is %.2f",
+		from, to, stats.Mean, len(stats.Windows), stats.StdDev)
+	return stats, nil
+}
+
+// trim returns the heights left of begin to end once the warm-up and cool-down are excluded,
+// a run too short to exclude them is measured whole
+func trim(client rpcx.Client, begin, end uint64, config *Config) (uint64, uint64, error) {
+	from, to := begin+config.WarmupBlocks, uint64(0)
+	if config.CooldownBlocks < end {
+		to = end - config.CooldownBlocks
+	}
+	if config.Warmup > 0 && from < to {
+		start, err := timestamp(client, begin)
+		if err != nil {
+			return 0, 0, err
+		}
+		h, err := search(client, from, to, func(ts int64) bool {
+			return ts >= start+int64(config.Warmup)
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		from = h
+	}
+	if config.Cooldown > 0 && from < to {
+		stop, err := timestamp(client, end)
+		if err != nil {
+			return 0, 0, err
+		}
+		h, err := search(client, from, to, func(ts int64) bool {
+			return ts > stop-int64(config.Cooldown)
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		to = h - 1
+	}
+	if from >= to {
+		log.Warnf("no block from %d to %d is left after the warm-up and cool-down, none is excluded", begin, end)
+		return begin, end, nil
+	}
+	return from, to, nil
+}
+
+// search returns the lowest height of lo to hi whose block timestamp satisfies f, or hi+1
+func search(client rpcx.Client, lo, hi uint64, f func(ts int64) bool) (uint64, error) {
+	var err error
+	i := sort.Search(int(hi-lo+1), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var ts int64
+		ts, err = timestamp(client, lo+uint64(i))
+		return err != nil || f(ts)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return lo + uint64(i), nil
+}
+
+// timestamp returns the timestamp of the block at height, in ns
+func timestamp(client rpcx.Client, height uint64) (int64, error) {
+	block, err := client.GetBlock(strconv.FormatUint(height, 10), pb.GetBlockRequest_HEIGHT, false)
+	if err != nil {
+		return 0, fmt.Errorf("get block %d: %w", height, err)
+	}
+	if block.BlockHeader == nil {
+		return 0, fmt.Errorf("block %d has no header", height)
+	}
+	return block.BlockHeader.Timestamp, nil
+}
@@ -0,0 +1,116 @@
+package tps
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/meshplus/bitxhub-model/pb"
+	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient serves blocks 1 to height with one block every second, and the tps of a
+// window by its first block
+type fakeClient struct {
+	rpcx.Client
+	height uint64
+	tps    map[uint64]uint64
+}
+
+func (c *fakeClient) GetBlock(value string, _ pb.GetBlockRequest_Type, _ bool) (*pb.Block, error) {
+	height, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if height == 0 || height > c.height {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	return &pb.Block{BlockHeader: &pb.BlockHeader{Number: height, Timestamp: int64(height) * int64(time.Second)}}, nil
+}
+
+func (c *fakeClient) GetTPS(begin, _ uint64) (uint64, error) {
+	return c.tps[begin], nil
+}
+
+func TestSearch(t *testing.T) {
+	client := &fakeClient{height: 10}
+	tests := []struct {
+		name   string
+		lo, hi uint64
+		after  time.Duration
+		want   uint64
+	}{
+		{"middle", 1, 10, 5 * time.Second, 5},
+		{"first", 1, 10, 0, 1},
+		{"last", 1, 10, 10 * time.Second, 10},
+		{"none", 1, 10, 11 * time.Second, 11},
+		{"sub range", 3, 6, time.Second, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := search(client, tt.lo, tt.hi, func(ts int64) bool {
+				return ts >= int64(tt.after)
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := search(&fakeClient{height: 4}, 1, 10, func(ts int64) bool { return false })
+	require.Error(t, err)
+}
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		from, to uint64
+	}{
+		{"nothing excluded", &Config{}, 1, 10},
+		{"by count", &Config{WarmupBlocks: 2, CooldownBlocks: 3}, 3, 7},
+		{"warm-up by timestamp", &Config{Warmup: 3 * time.Second}, 4, 10},
+		{"cool-down by timestamp", &Config{Cooldown: 3 * time.Second}, 1, 7},
+		{"both by timestamp", &Config{Warmup: 3 * time.Second, Cooldown: 3 * time.Second}, 4, 7},
+		{"count then timestamp", &Config{WarmupBlocks: 5, Warmup: 3 * time.Second}, 6, 10},
+		{"too short by count", &Config{WarmupBlocks: 6, CooldownBlocks: 6}, 1, 10},
+		{"too short by timestamp", &Config{Warmup: 6 * time.Second, Cooldown: 6 * time.Second}, 1, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := trim(&fakeClient{height: 10}, 1, 10, tt.config)
+			require.NoError(t, err)
+			require.Equal(t, tt.from, from)
+			require.Equal(t, tt.to, to)
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name    string
+		end     uint64
+		tps     map[uint64]uint64
+		windows []uint64
+		blocks  []uint64
+		mean    float64
+		stddev  float64
+	}{
+		{"steady", 9, map[uint64]uint64{1: 100, 5: 100}, []uint64{100, 100}, []uint64{4, 4}, 100, 0},
+		{"short last window", 10, map[uint64]uint64{1: 100, 5: 100, 9: 1000}, []uint64{100, 100, 1000}, []uint64{4, 4, 1}, 200, 282.84},
+		{"uneven", 9, map[uint64]uint64{1: 100, 5: 300}, []uint64{100, 300}, []uint64{4, 4}, 200, 100},
+		{"single block", 1, nil, nil, nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{height: tt.end, tps: tt.tps}
+			stats, err := Measure(client, 1, tt.end, &Config{Window: 4})
+			require.NoError(t, err)
+			require.Equal(t, tt.windows, stats.Windows)
+			require.Equal(t, tt.blocks, stats.Blocks)
+			require.InDelta(t, tt.mean, stats.Mean, 0.01)
+			require.InDelta(t, tt.stddev, stats.StdDev, 0.01)
+		})
+	}
+}
@@ -3,10 +3,10 @@ package bxh_tester
 import (
 	"math/rand"
 
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 type Model3 struct {
@@ -19,7 +19,7 @@ func (suite *Model3) SetupTest() {
 
 //tc：调用store合约，set 10M数据，交易回执显示失败
 func (suite *Model3) Test0301_Set10MDataIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	rand10MBytes := make([]byte, 1<<23+1<<21)
@@ -32,7 +32,7 @@ func (suite *Model3) Test0301_Set10MDataIsFail() {
 
 //tc：调用store合约，get的key为空，交易回执显示失败
 func (suite *Model3) Test0302_GetEmptyKeyIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	receipt, err := client.InvokeBVMContract(constant.StoreContractAddr.Address(), "Get", nil, pb.String("key_for_not_exist"))
@@ -43,7 +43,7 @@ func (suite *Model3) Test0302_GetEmptyKeyIsFail() {
 
 //tc：调用store合约，set的key为空，交易回执显示失败
 func (suite *Model3) Test0303_SetEmptyKeyIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	receipt, err := client.InvokeBVMContract(constant.StoreContractAddr.Address(), "Set", nil, pb.String(""), pb.String("value_for_empty"))
@@ -53,7 +53,7 @@ func (suite *Model3) Test0303_SetEmptyKeyIsFail() {
 
 //tc：调用store合约，set的value为空，交易回执显示失败
 func (suite *Model3) Test0304_SetEmptyValueIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	receipt, err := client.InvokeBVMContract(constant.StoreContractAddr.Address(), "Set", nil, pb.String("key_for_empty"), pb.String(""))
@@ -66,7 +66,7 @@ func (suite *Model3) Test0304_SetEmptyValueIsFail() {
 func (suite *Model3) Test0305_SetAndGetNormalIsSuccess() {
 	normalKey := "key_for_normal"
 	normalValue := "value_for_normal"
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res1, err := client.InvokeBVMContract(constant.StoreContractAddr.Address(), "Set", nil, pb.String(normalKey), pb.String(normalValue))
@@ -4,12 +4,12 @@ import (
 	"io/ioutil"
 	"strconv"
 
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-kit/types"
 	"github.com/meshplus/bitxhub-model/pb"
 
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 type Model4 struct {
@@ -23,7 +23,7 @@ func (suite *Model4) SetupTest() {
 //tc：部署账本合约后调用state_test_set方法设置键值对为（Alice，111），合约调用成功
 func (suite *Model4) Test0401_LegerSetIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -34,7 +34,7 @@ func (suite *Model4) Test0401_LegerSetIsSuccess() {
 //tc：部署账本合约后调用state_test_set方法设置键值对为（Alice，），合约调用失败
 func (suite *Model4) Test0402_LegerSetWithValueLossIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"))
@@ -46,7 +46,7 @@ func (suite *Model4) Test0402_LegerSetWithValueLossIsFail() {
 //tc：部署账本合约后调用state_test_set方法设置键值对为（，），合约调用失败
 func (suite *Model4) Test0403_LegerSetWithKVLossIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil)
@@ -58,7 +58,7 @@ func (suite *Model4) Test0403_LegerSetWithKVLossIsFail() {
 //tc：部署账本合约后调用state_test_set111方法设置键值对为（Alice，111），合约调用失败
 func (suite *Model4) Test0404_LegerSetWithErrorMethodIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set111", nil, rpcx.String("Alice"))
@@ -70,7 +70,7 @@ func (suite *Model4) Test0404_LegerSetWithErrorMethodIsFail() {
 //tc：部署账本合约后调用state_test_set方法设置键值对为（Alice，111）,重复调用，合约调用成功
 func (suite *Model4) Test0405_LegerSetRepeatIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -86,7 +86,7 @@ func (suite *Model4) Test0405_LegerSetRepeatIsSuccess() {
 //tc：部署账本合约后直接调用state_test_get方法获取Alice的值，合约调用失败
 func (suite *Model4) Test0406_LegerGetAliceWithoutSetIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_get", nil, rpcx.String("Alice"))
@@ -97,7 +97,7 @@ func (suite *Model4) Test0406_LegerGetAliceWithoutSetIsFail() {
 //tc：部署账本合约后直接调用state_test_get方法获取nil的值，合约调用失败
 func (suite *Model4) Test0407_GetNilWithoutSetIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_get", nil)
@@ -109,7 +109,7 @@ func (suite *Model4) Test0407_GetNilWithoutSetIsFail() {
 //tc：部署账本合约后设置键值对为（Alice，111），调用state_test_get方法获取Alice的值,合约调用成功
 func (suite *Model4) Test0408_SetAliceGetAliceIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -124,7 +124,7 @@ func (suite *Model4) Test0408_SetAliceGetAliceIsSuccess() {
 //tc：部署账本合约后设置键值对为（Alice，111），调用state_test_get方法获取Bob的值，合约调用失败
 func (suite *Model4) Test0409_SetAliceGetBobIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -138,7 +138,7 @@ func (suite *Model4) Test0409_SetAliceGetBobIsFail() {
 //tc：部署账本合约后设置键值对为（Alice，111），调用state_test_get方法获取nil的值，合约调用失败
 func (suite *Model4) Test0410_SetAliceGetNilIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -153,7 +153,7 @@ func (suite *Model4) Test0410_SetAliceGetNilIsFail() {
 //tc：部署账本合约后设置键值对为（Alice，111），调用state_test_get方法获取Alice的值，重复调用，合约调用成功
 func (suite *Model4) Test0411_SetAliceGetAliceRepeatIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -172,7 +172,7 @@ func (suite *Model4) Test0411_SetAliceGetAliceRepeatIsSuccess() {
 //tc：部署结果合约，获取当前的块高，合约调用成功
 func (suite *Model4) Test0412_GetCurrentHeightIsSuccess() {
 	address := suite.DeployResultContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "test_current_height", nil)
@@ -187,7 +187,7 @@ func (suite *Model4) Test0412_GetCurrentHeightIsSuccess() {
 //tc：部署结果合约，获取当前交易的交易hash，合约调用成功
 func (suite *Model4) Test0413_GetTxHashIsSuccess() {
 	address := suite.DeployResultContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "test_tx_hash", nil)
@@ -196,7 +196,7 @@ func (suite *Model4) Test0413_GetTxHashIsSuccess() {
 }
 
 func (suite *Snake) DeployLedgerContract() *types.Address {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	contract, err := ioutil.ReadFile("testdata/ledger_test_gc.wasm")
@@ -208,7 +208,7 @@ func (suite *Snake) DeployLedgerContract() *types.Address {
 }
 
 func (suite *Snake) DeployResultContract() *types.Address {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	contract, err := ioutil.ReadFile("testdata/result.wasm")
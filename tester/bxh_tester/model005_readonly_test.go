@@ -10,6 +10,7 @@ import (
 	"github.com/meshplus/bitxhub-kit/types"
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 type Model5 struct {
@@ -22,7 +23,7 @@ func (suite *Model5) SetupTest() {
 
 //tc:向中继链发送只读交易查询交易余额
 func (suite *Model5) Test0501_NormalReadOnlyIsSuccess() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	keyForNormal := "key_for_normal"
@@ -42,7 +43,7 @@ func (suite *Model5) Test0501_NormalReadOnlyIsSuccess() {
 
 //tc:向中继链提交只读交易接口发送可读写交易
 func (suite *Model5) Test0502_SendTx2ReadOnlyApiIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	rand.Seed(time.Now().UnixNano())
@@ -13,6 +13,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -260,7 +261,7 @@ func (suite *Model6) Test0613_RegisterAppchainWithNoExistSelfIsFail() {
 	suite.Require().Nil(err)
 	bytes, err := pk1.PublicKey().Bytes()
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -339,7 +340,7 @@ func (suite *Model6) Test0616_UpdateAppchainWithNoAdminIsFail() {
 	suite.Require().Nil(err)
 	err = suite.RegisterAppchain(pk, from, address)
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.UpdateAppchain(pk2, from, from, "desc", []byte(""), from)
 	suite.Require().NotNil(err)
@@ -357,7 +358,7 @@ func (suite *Model6) Test0617_UpdateAppchainIsSuccess() {
 
 //tc：通过不存在的应用链id更新应用链，应用链更新失败
 func (suite *Model6) Test0618_UpdateAppchainWithNoExistIDIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from, err := pk.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -367,7 +368,7 @@ func (suite *Model6) Test0618_UpdateAppchainWithNoExistIDIsFail() {
 
 //tc：通过空的应用链id更新应用链，应用链更新失败
 func (suite *Model6) Test0619_UpdateAppchainWithEmptyIDIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from, err := pk.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -435,7 +436,7 @@ func (suite *Model6) Test0624_UpdateAppchainWithFreeAdminIsSuccess() {
 	suite.Require().Nil(err)
 	bytes, err := pk1.PublicKey().Bytes()
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -485,7 +486,7 @@ func (suite *Model6) Test0625_UpdateAppchainWithEmptyAdminIsFail() {
 func (suite *Model6) Test0626_UpdateAppchainWithNoExistSelfIsFail() {
 	pk1, from1, address, err := suite.DeployRule()
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -630,7 +631,7 @@ func (suite *Model6) Test0636_UpdateAppchainWithAdminsFieldHaveProposalIsSuccess
 	suite.Require().Nil(err)
 	err = suite.RegisterAppchain(pk1, from1, address)
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -682,7 +683,7 @@ func (suite *Model6) Test0638_FreezeAppchainWithNoAdminIsFail() {
 	suite.Require().Nil(err)
 	err = suite.RegisterAppchain(pk1, from, address)
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk2)
 	res, err := client.InvokeBVMContract(constant.AppchainMgrContractAddr.Address(), "FreezeAppchain", nil, rpcx.String(from), rpcx.String("reason"))
@@ -803,7 +804,7 @@ func (suite *Model6) Test0648_ActivateAppchainWithNoAdminIsFail() {
 	suite.Require().Nil(err)
 	err = suite.ChainToFrozen(pk1, from, address)
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.ActivateAppchain(pk2, from)
 	suite.Require().NotNil(err)
@@ -926,7 +927,7 @@ func (suite *Model6) Test0658_LogoutAppchainWithNoAdminIsFail() {
 	suite.Require().Nil(err)
 	err = suite.RegisterAppchain(pk1, from, address)
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.LogoutAppchain(pk2, from)
 	suite.Require().NotNil(err)
@@ -1062,6 +1063,7 @@ func (suite *Snake) RegisterAppchain(pk crypto.PrivateKey, name, address string)
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Appchain, from.String(), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -1103,6 +1105,7 @@ func (suite *Snake) RegisterAppchainWithType(pk crypto.PrivateKey, typ, address,
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Appchain, from.String(), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -1387,7 +1390,7 @@ func (suite *Snake) CheckChainStatus(name string, expectStatus governance.Govern
 
 // GetChainStatusByName return chain status by name
 func (suite *Snake) GetChainStatusByName(name string) (governance.GovernanceStatus, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return "", err
 	}
@@ -11,6 +11,8 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 const (
@@ -42,7 +44,7 @@ func (suite *Model7) Test0701_DeployRuleIsSuccess() {
 
 //tc：部署验证规则字段为空，并提示错误信息
 func (suite *Model7) Test0702_DeployRuleIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	address, err := client.DeployContract([]byte(""), nil)
@@ -745,7 +747,7 @@ func (suite *Model7) Test0755_LogoutRuleWithForbiddenRuleIsFail() {
 
 // DeploySimpleRule deploy simple rule
 func (suite *Snake) DeploySimpleRule() (string, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return "", err
 	}
@@ -767,7 +769,7 @@ func (suite *Snake) DeployRule() (crypto.PrivateKey, string, string, error) {
 	if err != nil {
 		return nil, "", "", err
 	}
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -793,6 +795,7 @@ func (suite *Snake) RegisterRule(pk crypto.PrivateKey, ChainID, contractAddr str
 	if res.Status == pb.Receipt_FAILED {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Rule, manifest.RuleID(ChainID, contractAddr), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -869,7 +872,7 @@ func (suite *Snake) LogoutRule(pk crypto.PrivateKey, ChainID, contractAddr strin
 
 // Rules return all rules
 func (suite *Snake) Rules(chainID string) ([]Rule, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return nil, err
 	}
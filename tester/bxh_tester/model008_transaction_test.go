@@ -8,7 +8,6 @@ import (
 	"github.com/Rican7/retry"
 	"github.com/Rican7/retry/backoff"
 	"github.com/Rican7/retry/strategy"
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
@@ -131,7 +130,7 @@ func (suite *Model8) Test0806_TXWrongSigPrivateKeyIsFail() {
 		Timestamp: time.Now().UnixNano(),
 		Payload:   payload,
 	}
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	_, err = client.SendTransaction(tx, nil)
@@ -194,7 +193,7 @@ func (suite *Model8) Test0809_GetTxByHashIsSuccess() {
 	suite.Require().Nil(err)
 	var res *pb.GetTransactionResponse
 	err1 := retry.Retry(func(attempt uint) error {
-		pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+		pk, err := asym.GenerateKeyPair(repo.KeyType())
 		suite.Require().Nil(err)
 		client1 := suite.NewClient(pk)
 		res, err = client1.GetTransaction(hash)
@@ -212,7 +211,7 @@ func (suite *Model8) Test0809_GetTxByHashIsSuccess() {
 
 //tc：根据错误交易hash获取交易，交易获取失败
 func (suite *Model8) Test0810_GetTxByWrongHashIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	_, err = client.GetTransaction("0xc7F999b83Af6DF9e67d0a37Ee7e900bF38b3D014")
@@ -14,6 +14,7 @@ import (
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 )
 
 const (
@@ -40,7 +41,7 @@ func (suite *Model9) Test0901_SendIBTPWithStatusTransactionStatus_BEGIN() {
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -65,7 +66,7 @@ func (suite *Model9) Test0902_GetNoReceiptBeforeTimeOutWithStatusTransactionStat
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -86,14 +87,14 @@ func (suite *Model9) Test0903_SendIBTPNoExistChainWithStatusTransactionStatus_BE
 	suite.Require().Nil(err)
 	from1, err := pk1.PublicKey().Address()
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -118,14 +119,14 @@ func (suite *Model9) Test0904_GetReceiptSuccessWithStatusTransactionStatus_SUCCE
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
 	)
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
-	ibtp = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
@@ -147,14 +148,14 @@ func (suite *Model9) Test0905_GetReceiptFailWithStatusTransactionStatus_FAILURE(
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
 	)
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
-	ibtp = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
@@ -176,7 +177,7 @@ func (suite *Model9) Test0906_GetReceiptAfterTimeOutWithStatusTransactionStatus_
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -189,7 +190,7 @@ func (suite *Model9) Test0906_GetReceiptAfterTimeOutWithStatusTransactionStatus_
 	status, err := suite.GetStatus(ibtp.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN_ROLLBACK, status)
-	ibtp = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_ROLLBACK, proof)
+	ibtp = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_ROLLBACK, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
@@ -215,8 +216,8 @@ func (suite *Model9) Test0907_SendIBTPSWithStatusTransactionStatus_BEGIN() {
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -253,8 +254,8 @@ func (suite *Model9) Test0908_GetOneReceiptFailWithStatusTransactionStatus_BEGIN
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -272,7 +273,7 @@ func (suite *Model9) Test0908_GetOneReceiptFailWithStatusTransactionStatus_BEGIN
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
@@ -301,8 +302,8 @@ func (suite *Model9) Test0909_GetNoAllReceiptBeforeTimeOutWithStatusTransactionS
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -320,7 +321,7 @@ func (suite *Model9) Test0909_GetNoAllReceiptBeforeTimeOutWithStatusTransactionS
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
@@ -352,8 +353,8 @@ func (suite *Model9) Test0910_GetAllReceiptSuccessWithStatusTransactionStatus_SU
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -371,11 +372,11 @@ func (suite *Model9) Test0910_GetAllReceiptSuccessWithStatusTransactionStatus_SU
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
-	ibtp2 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp2 = suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp2, payload, proof)
 	suite.Require().Nil(err)
@@ -404,8 +405,8 @@ func (suite *Model9) Test0911_GetAllReceiptFailWithStatusTransactionStatus_FAILU
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -423,11 +424,11 @@ func (suite *Model9) Test0911_GetAllReceiptFailWithStatusTransactionStatus_FAILU
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
-	ibtp2 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp2 = suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp2, payload, proof)
 	suite.Require().Nil(err)
@@ -456,8 +457,8 @@ func (suite *Model9) Test0912_GetAllReceiptTimeOutWithStatusTransactionStatus_RO
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -478,11 +479,11 @@ func (suite *Model9) Test0912_GetAllReceiptTimeOutWithStatusTransactionStatus_RO
 	for i := 0; i < 15; i++ {
 		suite.SendTransaction(pk1)
 	}
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_ROLLBACK, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_ROLLBACK, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
-	ibtp2 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_RECEIPT_ROLLBACK, proof)
+	ibtp2 = suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_RECEIPT_ROLLBACK, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp2, payload, proof)
 	suite.Require().Nil(err)
@@ -538,7 +539,7 @@ func (suite *Model9) Test0913_AccountHaveInsufficientGas() {
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload = suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -576,7 +577,7 @@ func (suite *Model9) Test0913_AccountHaveInsufficientGas() {
 	suite.Require().Equal(int64(leftFee), account.Balance.Int64())
 
 	// mock ibtp2, send ibtp2 failed because insufficient gas
-	ibtp2 := suite.MockIBTP(2, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(2, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload = suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("2")},
@@ -617,7 +618,7 @@ func (suite *Model9) Test0913_AccountHaveInsufficientGas() {
 
 // PrepareServer prepare a server and return privateKey
 func (suite *Snake) PrepareServer() (crypto.PrivateKey, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return nil, err
 	}
@@ -629,7 +630,7 @@ func (suite *Snake) PrepareServer() (crypto.PrivateKey, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = suite.RegisterServer(pk, from.String(), "mychannel&transfer", from.String(), "CallContract")
+	err = suite.RegisterServer(pk, from.String(), service.DefaultServiceID, from.String(), "CallContract")
 	if err != nil {
 		return nil, err
 	}
@@ -638,7 +639,7 @@ func (suite *Snake) PrepareServer() (crypto.PrivateKey, error) {
 
 // GetStatus get tx status
 func (suite *Model9) GetStatus(txId string) (pb.TransactionStatus, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return -1, err
 	}
@@ -7,11 +7,11 @@ import (
 
 	"github.com/looplab/fsm"
 	"github.com/meshplus/bitxhub-core/governance"
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -1154,6 +1154,7 @@ func (suite *Snake) RegisterNode(nodeAccount, nodeType, nodePid string, nodeVpId
 	if err != nil {
 		return err
 	}
+	record(manifest.Node, nodeAccount, nil)
 	err = suite.VotePass(proposal)
 	if err != nil {
 		return err
@@ -1364,7 +1365,7 @@ func (suite *Snake) NodeToUpdating(nodeAccount, nodeType, nodePid string, nodeVp
 
 // CheckNodeStatus check node status
 func (suite *Snake) CheckNodeStatus(account string, status governance.GovernanceStatus) error {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return err
 	}
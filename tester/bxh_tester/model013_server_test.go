@@ -12,6 +12,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -111,7 +112,7 @@ func (suite *Model13) Test1305_RegisterServerWithRelayAdminIsFail() {
 
 //tc：非应用链管理员注册服务，服务注册失败
 func (suite *Model13) Test1306_RegisterServerWithNoAdminIsFail() {
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	pk2, chainID, address, err := suite.DeployRule()
 	suite.Require().Nil(err)
@@ -350,7 +351,7 @@ func (suite *Model13) Test1327_UpdateServerWithNoAdminIsFail() {
 	suite.Require().Nil(err)
 	err = suite.RegisterServer(pk1, chainID, chainID, chainID, "CallContract")
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.UpdateService(pk2, chainID+":"+chainID, chainID)
 	suite.Require().NotNil(err)
@@ -1223,6 +1224,7 @@ func (suite *Snake) RegisterServer(pk crypto.PrivateKey, chainID, serviceID, nam
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Service, chainID+":"+serviceID, pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -1343,7 +1345,7 @@ func (suite *Model13) LogoutService(pk crypto.PrivateKey, chainServiceID string)
 
 // CheckServerStatus check server status
 func (suite *Snake) CheckServerStatus(serverID string, status governance.GovernanceStatus) error {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return err
 	}
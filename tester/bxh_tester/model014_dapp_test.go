@@ -12,6 +12,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -49,7 +50,7 @@ func (suite *Model14) SetupTest() {
 //tc：根据存在的合约地址注册dapp，dapp注册成功
 func (suite *Model14) Test1401_RegisterDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -57,7 +58,7 @@ func (suite *Model14) Test1401_RegisterDappIsSuccess() {
 
 //tc：根据不存在的合约地址注册dapp，dapp注册失败
 func (suite *Model14) Test1402_RegisterDappWithNoExistAddrIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, "0x0000000000000000000000000000000000000000")
 	suite.Require().NotNil(err)
@@ -66,7 +67,7 @@ func (suite *Model14) Test1402_RegisterDappWithNoExistAddrIsFail() {
 //tc：dapp使用已经绑定dapp的合约地址注册dapp，dapp注册失败
 func (suite *Model14) Test1403_RegisterDappWithUsedAddrIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -78,7 +79,7 @@ func (suite *Model14) Test1403_RegisterDappWithUsedAddrIsFail() {
 func (suite *Model14) Test1404_UpdateDappIsSuccess() {
 	address1 := suite.DeployLedgerContract()
 	address2 := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address1.String())
 	suite.Require().Nil(err)
@@ -93,7 +94,7 @@ func (suite *Model14) Test1404_UpdateDappIsSuccess() {
 //tc：根据不存在的合约地址更新dapp，dapp更新失败
 func (suite *Model14) Test1405_UpdateDappWithNoExistAddrIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -104,12 +105,12 @@ func (suite *Model14) Test1405_UpdateDappWithNoExistAddrIsFail() {
 //tc:根据已绑定的合约地址更新dapp，dapp更新失败
 func (suite *Model14) Test1406_UpdateDappWithUsedAddrIsFail() {
 	address1 := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk1, address1.String())
 	suite.Require().Nil(err)
 	address2 := suite.DeployLedgerContract()
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk2, address2.String())
 	suite.Require().Nil(err)
@@ -124,7 +125,7 @@ func (suite *Model14) Test1406_UpdateDappWithUsedAddrIsFail() {
 //tc：dapp处于unavailable状态更新dapp，dapp更新失败
 func (suite *Model14) Test1407_UpdateDappWithUnavailableDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUnavailable(pk, address.String())
 	suite.Require().Nil(err)
@@ -135,7 +136,7 @@ func (suite *Model14) Test1407_UpdateDappWithUnavailableDappIsFail() {
 //tc：dapp处于activating状态更新dapp，dapp更新失败
 func (suite *Model14) Test1408_UpdateDappWithActivatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToActivating(pk, address.String())
 	suite.Require().Nil(err)
@@ -146,7 +147,7 @@ func (suite *Model14) Test1408_UpdateDappWithActivatingDappIsFail() {
 //tc：dapp处于updating状态更新dapp，dapp更新失败
 func (suite *Model14) Test1409_UpdateDappWithUpdatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUpdating(pk, address.String())
 	suite.Require().Nil(err)
@@ -157,7 +158,7 @@ func (suite *Model14) Test1409_UpdateDappWithUpdatingDappIsFail() {
 //tc：dapp处于freezing状态更新dapp，dapp更新失败
 func (suite *Model14) Test1410_UpdateDappWithFreezingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFreezing(pk, address.String())
 	suite.Require().Nil(err)
@@ -168,7 +169,7 @@ func (suite *Model14) Test1410_UpdateDappWithFreezingDappIsFail() {
 //tc：dapp处于frozen状态更新dapp，dapp更新失败
 func (suite *Model14) Test14011_UpdateDappWithFrozenDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFrozen(pk, address.String())
 	suite.Require().Nil(err)
@@ -179,7 +180,7 @@ func (suite *Model14) Test14011_UpdateDappWithFrozenDappIsFail() {
 //tc：根据存在的合约地址冻结dapp，dapp冻结成功
 func (suite *Model14) Test1412_FreezeDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -189,7 +190,7 @@ func (suite *Model14) Test1412_FreezeDappIsSuccess() {
 
 //tc：根据不存在的合约地址冻结dapp，dapp冻结失败
 func (suite *Model14) Test1413_FreezeDappWithNoExistAddrIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.FreezeDapp(pk)
 	suite.Require().NotNil(err)
@@ -198,7 +199,7 @@ func (suite *Model14) Test1413_FreezeDappWithNoExistAddrIsFail() {
 //tc：dapp处于Unavailable状态冻结dapp，dapp冻结失败
 func (suite *Model14) Test1414_FreezeDappWithUnavailableDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUnavailable(pk, address.String())
 	suite.Require().Nil(err)
@@ -209,7 +210,7 @@ func (suite *Model14) Test1414_FreezeDappWithUnavailableDappIsFail() {
 //tc：dapp处于activating状态冻结dapp，dapp冻结失败
 func (suite *Model14) Test1415_FreezeDappWithActivatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToActivating(pk, address.String())
 	suite.Require().Nil(err)
@@ -220,7 +221,7 @@ func (suite *Model14) Test1415_FreezeDappWithActivatingDappIsFail() {
 //tc：dapp处于updating状态冻结dapp，dapp冻结失败
 func (suite *Model14) Test1416_FreezeDappWithUpdatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUpdating(pk, address.String())
 	suite.Require().Nil(err)
@@ -231,7 +232,7 @@ func (suite *Model14) Test1416_FreezeDappWithUpdatingDappIsFail() {
 //tc：dapp处于freezing状态冻结dapp，dapp冻结失败
 func (suite *Model14) Test1417_FreezeDappWithFreezingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFreezing(pk, address.String())
 	suite.Require().Nil(err)
@@ -242,7 +243,7 @@ func (suite *Model14) Test1417_FreezeDappWithFreezingDappIsFail() {
 //tc：dapp处于frozen状态冻结dapp，dapp冻结失败
 func (suite *Model14) Test1418_FreezeDappWithFrozenDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFrozen(pk, address.String())
 	suite.Require().Nil(err)
@@ -253,7 +254,7 @@ func (suite *Model14) Test1418_FreezeDappWithFrozenDappIsFail() {
 //tc：根据存在的合约地址激活dapp，dapp激活成功
 func (suite *Model14) Test1419_ActivateDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -267,7 +268,7 @@ func (suite *Model14) Test1419_ActivateDappIsSuccess() {
 
 //tc：根据不存在的合约地址激活dapp，dapp激活失败
 func (suite *Model14) Test1420_ActivateDappWithNoExistAddrIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.ActivateDapp(pk)
 	suite.Require().NotNil(err)
@@ -276,7 +277,7 @@ func (suite *Model14) Test1420_ActivateDappWithNoExistAddrIsFail() {
 //tc：dapp处于available状态激活dapp，dapp激活失败
 func (suite *Model14) Test1421_ActivateDappWithAvailableDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToAvailable(pk, address.String())
 	suite.Require().Nil(err)
@@ -287,7 +288,7 @@ func (suite *Model14) Test1421_ActivateDappWithAvailableDappIsFail() {
 //tc：dapp处于unavailable状态激活dapp，dapp激活失败
 func (suite *Model14) Test1422_ActivateDappWithUnavailableDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUnavailable(pk, address.String())
 	suite.Require().Nil(err)
@@ -298,7 +299,7 @@ func (suite *Model14) Test1422_ActivateDappWithUnavailableDappIsFail() {
 //tc：dapp处于activating状态激活dapp，dapp激活失败
 func (suite *Model14) Test1423_ActivateDappWithActivatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToActivating(pk, address.String())
 	suite.Require().Nil(err)
@@ -309,7 +310,7 @@ func (suite *Model14) Test1423_ActivateDappWithActivatingDappIsFail() {
 //tc：dapp处于updating状态激活dapp，dapp激活失败
 func (suite *Model14) Test1424_ActivateDappWithUpdatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUpdating(pk, address.String())
 	suite.Require().Nil(err)
@@ -320,7 +321,7 @@ func (suite *Model14) Test1424_ActivateDappWithUpdatingDappIsFail() {
 //tc：dapp处于freezing状态激活dapp，dapp激活失败
 func (suite *Model14) Test1425_ActivateDappWithFreezingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFreezing(pk, address.String())
 	suite.Require().Nil(err)
@@ -331,11 +332,11 @@ func (suite *Model14) Test1425_ActivateDappWithFreezingDappIsFail() {
 //tc：根据存在的合约地址转让dapp，dapp转让成功
 func (suite *Model14) Test1426_TransferDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().Nil(err)
@@ -345,9 +346,9 @@ func (suite *Model14) Test1426_TransferDappIsSuccess() {
 
 //tc：根据不存在的合约地址转让dapp，dapp转让失败
 func (suite *Model14) Test1427_TransferDappWithNoExistDappIsFail() {
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -356,11 +357,11 @@ func (suite *Model14) Test1427_TransferDappWithNoExistDappIsFail() {
 //tc：dapp处于unavailable状态转让dapp，dapp转让失败
 func (suite *Model14) Test1428_TransferDappWithUnavailableDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUnavailable(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -369,11 +370,11 @@ func (suite *Model14) Test1428_TransferDappWithUnavailableDappIsFail() {
 //tc：dapp处于activating状态转让dapp，dapp转让失败
 func (suite *Model14) Test1429_TransferDappWithActivatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToActivating(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -382,11 +383,11 @@ func (suite *Model14) Test1429_TransferDappWithActivatingDappIsFail() {
 //tc：dapp处于updating状态转让dapp，dapp转让失败
 func (suite *Model14) Test1430_TransferDappWithUpdatingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToUpdating(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -395,11 +396,11 @@ func (suite *Model14) Test1430_TransferDappWithUpdatingDappIsFail() {
 //tc：dapp处于freezing状态转让dapp，dapp转让失败
 func (suite *Model14) Test1431_TransferDappWithFreezingDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFreezing(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -408,11 +409,11 @@ func (suite *Model14) Test1431_TransferDappWithFreezingDappIsFail() {
 //tc：dapp处于frozen状态转让dapp，dapp转让失败
 func (suite *Model14) Test1432_TransferDappWithFrozenDappIsFail() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.DappToFrozen(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -421,7 +422,7 @@ func (suite *Model14) Test1432_TransferDappWithFrozenDappIsFail() {
 //tc：dapp转让给自己，dapp转让失败
 func (suite *Model14) Test1433_TransferDappWithSelfIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -432,11 +433,11 @@ func (suite *Model14) Test1433_TransferDappWithSelfIsFail() {
 //tc：dapp接收确认不存在的dapp转移，确认失败
 func (suite *Model14) Test1433_ConfirmTransferWithNoExistTransferIsFail() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.ConfirmTransfer(pk1, pk2)
 	suite.Require().NotNil(err)
@@ -445,7 +446,7 @@ func (suite *Model14) Test1433_ConfirmTransferWithNoExistTransferIsFail() {
 //tc：评价存在dapp，dapp评价成功
 func (suite *Model14) Test1434_EvaluateDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -455,7 +456,7 @@ func (suite *Model14) Test1434_EvaluateDappIsSuccess() {
 
 //tc：评价不存在dapp，dapp评价失败
 func (suite *Model14) Test1435_EvaluateDappWithNoExistDappIsFail() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.EvaluateDapp(pk, suite.MockDappID(pk), "good", 5.0)
 	suite.Require().NotNil(err)
@@ -464,7 +465,7 @@ func (suite *Model14) Test1435_EvaluateDappWithNoExistDappIsFail() {
 //tc：评价评分不在[0-5]，dapp评价失败
 func (suite *Model14) Test1436_EvaluateDappWithErrorScoreIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -475,7 +476,7 @@ func (suite *Model14) Test1436_EvaluateDappWithErrorScoreIsFail() {
 //tc：重复评价dapp，dapp评价失败
 func (suite *Model14) Test1437_EvaluateDappWithRepeatEvaluateIsFail() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -504,6 +505,7 @@ func (suite *Snake) RegisterDapp(pk crypto.PrivateKey, conAddrs string) error {
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Dapp, suite.MockDappID(pk), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -658,7 +660,7 @@ func (suite *Model14) EvaluateDapp(pk crypto.PrivateKey, id, desc string, score
 
 // CheckDappStatus check dapp status
 func (suite *Snake) CheckDappStatus(id string, status governance.GovernanceStatus) error {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return err
 	}
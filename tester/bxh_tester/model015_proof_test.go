@@ -6,8 +6,7 @@ package bxh_tester
 //	"time"
 //
 //	"github.com/gobuffalo/packr"
-//	"github.com/meshplus/bitxhub-kit/crypto"
-//	"github.com/meshplus/bitxhub-kit/crypto/asym"
+////	"github.com/meshplus/bitxhub-kit/crypto/asym"
 //	"github.com/meshplus/bitxhub-model/constant"
 //	"github.com/meshplus/bitxhub-model/pb"
 //	"github.com/meshplus/premo/internal/repo"
@@ -24,7 +23,7 @@ package bxh_tester
 //
 ////tc：proof正确，跨链交易执行成功
 //func (suite Model15) Test1301_IBTPIsSuccess() {
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	from, err := pk.PublicKey().Address()
 //	suite.Require().Nil(err)
@@ -34,7 +33,7 @@ package bxh_tester
 //	for i := 0; i < 10000; i++ {
 //		box := packr.NewBox(repo.ConfigPath)
 //		proof, err := box.Find("proof_1.0.0_rc_complex")
-//		ibtp := suite.MockIBTP(uint64(i+1), "1356:"+from.String()+":mychannel&transfer", "1356:"+from.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+//		ibtp := suite.MockIBTP(uint64(i+1), fullID(from.String()), fullID(from.String()), pb.IBTP_INTERCHAIN, proof)
 //		payload := suite.MockContent(
 //			"interchainCharge",
 //			[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -50,7 +49,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -68,7 +67,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -86,7 +85,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -104,7 +103,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -122,7 +121,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -140,7 +139,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -158,7 +157,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -176,7 +175,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -194,7 +193,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -212,7 +211,7 @@ package bxh_tester
 //	box := packr.NewBox(repo.ConfigPath)
 //	proof, err := box.Find("proof_1.0.0_rc_complex_error")
 //	suite.Require().Nil(err)
-//	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+//	pk, err := asym.GenerateKeyPair(repo.KeyType())
 //	suite.Require().Nil(err)
 //	err = suite.RegisterAppchain(pk, suite.GetChainID(pk), FabricRuleAddr)
 //	suite.Require().Nil(err)
@@ -8,7 +8,6 @@ import (
 
 	"github.com/looplab/fsm"
 	"github.com/meshplus/bitxhub-core/governance"
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
@@ -456,7 +455,7 @@ func (suite *Model17) Test1735_UpdateStrategyBeforeAdminAdded() {
 	suite.Require().Nil(err)
 	err = suite.UpdateStrategy(StrategyMgr, SimpleMajority, "t==4")
 	suite.Require().Nil(err)
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from, err := pk.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -500,7 +499,7 @@ func (suite *Model17) UpdateStrategy(model, typ, extra string) error {
 
 // GetStrategyByType get strategy by model type
 func (suite *Model17) GetStrategyByType(typ string) (*ProposalStrategy, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return nil, err
 	}
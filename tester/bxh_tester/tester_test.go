@@ -1,12 +1,34 @@
 package bxh_tester
 
 import (
+	"flag"
 	"testing"
 
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
+
 	"github.com/stretchr/testify/suite"
 )
 
+var (
+	cryptoAlgorithm = flag.String("crypto", "Secp256k1", "algorithm of tester accounts: Secp256k1, SM2, ECDSA-P256")
+	bxhID           = flag.String("bxh_id", service.DefaultBxhID, "chain id of the relay chain under test, the prefix of full service ids")
+)
+
 func TestTester(t *testing.T) {
+	if err := repo.SetKeyType(*cryptoAlgorithm); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("tester accounts use %s", repo.KeyTypeName())
+	cfg.services = service.NewBuilder(*bxhID)
+	var err error
+	created, err = manifest.Create("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer created.Close()
+	t.Logf("run %s records the governed objects the tester registers, remove them with `premo cleanup --run %s`", created.ID, created.ID)
 	suite.Run(t, &Model1{&Snake{}})
 	suite.Run(t, &Model2{&Snake{}})
 	suite.Run(t, &Model3{&Snake{}})
@@ -3,7 +3,6 @@ package bxh_tester
 import (
 	"crypto/sha256"
 	"fmt"
-	"strings"
 	"sync/atomic"
 	"time"
 
@@ -18,15 +17,17 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/admin"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 )
 
 const (
-	approveVote   = "approve"
-	rejectVote    = "reject"
-	ignoreVoteErr = "the current status of the proposal is approve and cannot be voted on"
+	approveVote = "approve"
+	rejectVote  = "reject"
 )
 
 var cfg = &config{
@@ -36,13 +37,21 @@ var cfg = &config{
 		"localhost:60013",
 		"localhost:60014",
 	},
-	logger: logrus.New(),
+	logger:   logrus.New(),
+	services: service.NewBuilder(service.DefaultBxhID),
 }
 
 type config struct {
-	addrs  []string
-	logger rpcx.Logger
+	addrs    []string
+	logger   rpcx.Logger
+	services service.Builder
 }
+
+// fullID returns the full ID of the default service on chainID under the relay chain of cfg
+func fullID(chainID string) string {
+	return cfg.services.Default(chainID).String()
+}
+
 type Snake struct {
 	suite.Suite
 }
@@ -72,6 +81,21 @@ var nonce1 uint64
 var nonce2 uint64
 var nonce3 uint64
 var nonce4 uint64
+var adminNonces map[string]*uint64
+
+// the admin set and its client are loaded once, decrypting every key per vote is slow
+var admins *admin.Set
+var adminClient *rpcx.ChainClient
+
+// created is the manifest of the governed objects registered by the tester
+var created *manifest.Manifest
+
+// record adds a registered object to the manifest, owner is the key logging it out, nil for the admins
+func record(kind, id string, owner crypto.PrivateKey) {
+	if err := created.Record(kind, id, owner); err != nil {
+		cfg.logger.Warningf("record %s %s: %s", kind, id, err)
+	}
+}
 
 func (suite *Snake) SetupSuite() {
 	key1, node1Addr, err := repo.Node1Priv()
@@ -107,6 +131,20 @@ func (suite *Snake) SetupSuite() {
 	nonce, err = client.GetPendingNonceByAccount(node4Addr.String())
 	suite.Require().Nil(err)
 	nonce4 = nonce - 1
+	adminNonces = map[string]*uint64{
+		node1Addr.String(): &nonce1,
+		node2Addr.String(): &nonce2,
+		node3Addr.String(): &nonce3,
+		node4Addr.String(): &nonce4,
+	}
+	admins, err = admin.Load()
+	suite.Require().Nil(err)
+	adminClient, err = rpcx.New(
+		rpcx.WithNodesInfo(node0),
+		rpcx.WithLogger(cfg.logger),
+		rpcx.WithPrivateKey(admins.Admins[0].Key),
+	)
+	suite.Require().Nil(err)
 }
 
 // NewClient return client by privateKey
@@ -136,58 +174,17 @@ func (suite *Snake) VoteReject(id string) error {
 	return suite.Vote(id, rejectVote)
 }
 
-//Vote `vote` proposal by id and info with four admin
+// Vote `vote` proposal by id and info with the admin set
 func (suite *Snake) Vote(id, info string) error {
-	key1, _, err := repo.Node1Priv()
-	if err != nil {
-		return err
-	}
-	res, err := suite.vote(key1, atomic.AddUint64(&nonce1, 1), rpcx.String(id), rpcx.String(info), rpcx.String("Vote"))
-	if err != nil {
-		// if proposal had been approved, need not vote
-		if strings.Contains(err.Error(), ignoreVoteErr) {
-			return nil
+	voter := admin.NewVoter(adminClient, admins, "Vote")
+	// node1 to node4 also send other txs of the suite, share their nonces
+	voter.Nonce = func(a *admin.Admin) (uint64, error) {
+		if nonce, ok := adminNonces[a.Address.String()]; ok {
+			return atomic.AddUint64(nonce, 1), nil
 		}
-		return err
-	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-	key2, _, err := repo.Node2Priv()
-	if err != nil {
-		return err
-	}
-	res, err = suite.vote(key2, atomic.AddUint64(&nonce2, 1), rpcx.String(id), rpcx.String(info), rpcx.String("Vote"))
-	if err != nil {
-		// if proposal had been approved, need not vote
-		if strings.Contains(err.Error(), ignoreVoteErr) {
-			return nil
-		}
-		return err
+		return a.NextNonce(adminClient)
 	}
-	if res.Status != pb.Receipt_SUCCESS {
-		return fmt.Errorf("vote err: %s", string(res.Ret))
-	}
-	key3, _, err := repo.Node3Priv()
-	if err != nil {
-		return err
-	}
-
-	// if bxh had received 2 reject vote, proposal status is reject, because this proposal is impossible be approved
-	if info == approveVote {
-		res, err = suite.vote(key3, atomic.AddUint64(&nonce3, 1), rpcx.String(id), rpcx.String(info), rpcx.String("Vote"))
-		if err != nil {
-			// if proposal had been approved, need not vote
-			if strings.Contains(err.Error(), ignoreVoteErr) {
-				return nil
-			}
-			return err
-		}
-		if res.Status != pb.Receipt_SUCCESS {
-			return fmt.Errorf("vote err: %s", string(res.Ret))
-		}
-	}
-	return nil
+	return voter.Vote(id, info)
 }
 
 // vote `vote` proposal
@@ -1,10 +1,10 @@
 package bxh_tester
 
 import (
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 type Model3 struct {
@@ -16,7 +16,7 @@ type Model3 struct {
 func (suite *Model3) Test0301_SetAndGetNormalIsSuccess() {
 	normalKey := "key_for_normal"
 	normalValue := "value_for_normal"
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res1, err := client.InvokeBVMContract(constant.StoreContractAddr.Address(), "Set", nil, pb.String(normalKey), pb.String(normalValue))
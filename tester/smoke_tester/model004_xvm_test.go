@@ -4,12 +4,12 @@ import (
 	"io/ioutil"
 	"strconv"
 
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-kit/types"
 	"github.com/meshplus/bitxhub-model/pb"
 
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 type Model4 struct {
@@ -19,7 +19,7 @@ type Model4 struct {
 //tc：部署账本合约后调用state_test_set方法设置键值对为（Alice，111），合约调用成功
 func (suite *Model4) Test0401_LegerSetIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -30,7 +30,7 @@ func (suite *Model4) Test0401_LegerSetIsSuccess() {
 //tc：部署账本合约后调用state_test_set方法设置键值对为（Alice，111）,重复调用，合约调用成功
 func (suite *Model4) Test0402_LegerSetRepeatIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -46,7 +46,7 @@ func (suite *Model4) Test0402_LegerSetRepeatIsSuccess() {
 //tc：部署账本合约后设置键值对为（Alice，111），调用state_test_get方法获取Alice的值,合约调用成功
 func (suite *Model4) Test0403_SetAliceGetAliceIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -61,7 +61,7 @@ func (suite *Model4) Test0403_SetAliceGetAliceIsSuccess() {
 //tc：部署账本合约后设置键值对为（Alice，111），调用state_test_get方法获取Alice的值，重复调用，合约调用成功
 func (suite *Model4) Test0404_SetAliceGetAliceRepeatIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "state_test_set", nil, rpcx.String("Alice"), rpcx.String("111"))
@@ -80,7 +80,7 @@ func (suite *Model4) Test0404_SetAliceGetAliceRepeatIsSuccess() {
 //tc：部署结果合约，获取当前的块高，合约调用成功
 func (suite *Model4) Test0405_GetCurrentHeightIsSuccess() {
 	address := suite.DeployResultContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "test_current_height", nil)
@@ -93,7 +93,7 @@ func (suite *Model4) Test0405_GetCurrentHeightIsSuccess() {
 //tc：部署结果合约，获取当前交易的交易hash，合约调用成功
 func (suite *Model4) Test0406_GetTxHashIsSuccess() {
 	address := suite.DeployResultContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	res, err := client.InvokeXVMContract(address, "test_tx_hash", nil)
@@ -102,7 +102,7 @@ func (suite *Model4) Test0406_GetTxHashIsSuccess() {
 }
 
 func (suite *Snake) DeployLedgerContract() *types.Address {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	contract, err := ioutil.ReadFile("testdata/ledger_test_gc.wasm")
@@ -114,7 +114,7 @@ func (suite *Snake) DeployLedgerContract() *types.Address {
 }
 
 func (suite *Snake) DeployResultContract() *types.Address {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	contract, err := ioutil.ReadFile("testdata/result.wasm")
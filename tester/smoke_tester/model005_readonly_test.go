@@ -9,6 +9,7 @@ import (
 	"github.com/meshplus/bitxhub-kit/types"
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 type Model5 struct {
@@ -17,7 +18,7 @@ type Model5 struct {
 
 //tc:向中继链发送只读交易查询交易余额
 func (suite *Model5) Test0501_NormalReadOnlyIsSuccess() {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	keyForNormal := "key_for_normal"
@@ -13,6 +13,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -129,7 +130,7 @@ func (suite *Model6) Test0606_UpdateAppchainWithFreeAdminIsSuccess() {
 	suite.Require().Nil(err)
 	bytes, err := pk1.PublicKey().Bytes()
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -229,7 +230,7 @@ func (suite *Model6) Test0610_UpdateAppchainWithAdminsFieldHaveProposalIsSuccess
 	suite.Require().Nil(err)
 	err = suite.RegisterAppchain(pk1, from1, address)
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
@@ -419,6 +420,7 @@ func (suite *Snake) RegisterAppchain(pk crypto.PrivateKey, name, address string)
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Appchain, from.String(), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -462,6 +464,7 @@ func (suite *Snake) RegisterAppchainWithType(pk crypto.PrivateKey, typ, address,
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Appchain, from.String(), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -746,7 +749,7 @@ func (suite *Snake) CheckChainStatus(name string, expectStatus governance.Govern
 
 // GetChainStatusByName return chain status by name
 func (suite *Snake) GetChainStatusByName(name string) (governance.GovernanceStatus, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return "", err
 	}
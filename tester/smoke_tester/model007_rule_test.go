@@ -11,6 +11,8 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
+	"github.com/meshplus/premo/internal/repo"
 )
 
 const (
@@ -325,7 +327,7 @@ func (suite *Model7) Test0726_LogoutRuleWithBindableRuleIsSuccess() {
 
 // DeploySimpleRule deploy simple rule
 func (suite *Snake) DeploySimpleRule() (string, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return "", err
 	}
@@ -347,7 +349,7 @@ func (suite *Snake) DeployRule() (crypto.PrivateKey, string, string, error) {
 	if err != nil {
 		return nil, "", "", err
 	}
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -373,6 +375,7 @@ func (suite *Snake) RegisterRule(pk crypto.PrivateKey, ChainID, contractAddr str
 	if res.Status == pb.Receipt_FAILED {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Rule, manifest.RuleID(ChainID, contractAddr), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -449,7 +452,7 @@ func (suite *Snake) LogoutRule(pk crypto.PrivateKey, ChainID, contractAddr strin
 
 // Rules return all rules
 func (suite *Snake) Rules(chainID string) ([]Rule, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return nil, err
 	}
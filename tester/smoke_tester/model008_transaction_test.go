@@ -7,7 +7,6 @@ import (
 	"github.com/Rican7/retry"
 	"github.com/Rican7/retry/backoff"
 	"github.com/Rican7/retry/strategy"
-	"github.com/meshplus/bitxhub-kit/crypto"
 	"github.com/meshplus/bitxhub-kit/crypto/asym"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
@@ -43,7 +42,7 @@ func (suite *Model8) Test0801_GetTxByHashIsSuccess() {
 	suite.Require().Nil(err)
 	var res *pb.GetTransactionResponse
 	err1 := retry.Retry(func(attempt uint) error {
-		pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+		pk, err := asym.GenerateKeyPair(repo.KeyType())
 		suite.Require().Nil(err)
 		client1 := suite.NewClient(pk)
 		res, err = client1.GetTransaction(hash)
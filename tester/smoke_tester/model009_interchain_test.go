@@ -12,6 +12,7 @@ import (
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
 	"github.com/meshplus/premo/internal/repo"
+	"github.com/meshplus/premo/internal/service"
 )
 
 type Model9 struct {
@@ -31,7 +32,7 @@ func (suite *Model9) Test0901_SendIBTPWithStatusTransactionStatus_BEGIN() {
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -56,7 +57,7 @@ func (suite *Model9) Test0902_GetNoReceiptBeforeTimeOutWithStatusTransactionStat
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -77,14 +78,14 @@ func (suite *Model9) Test0903_SendIBTPNoExistChainWithStatusTransactionStatus_BE
 	suite.Require().Nil(err)
 	from1, err := pk1.PublicKey().Address()
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from2, err := pk2.PublicKey().Address()
 	suite.Require().Nil(err)
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -109,14 +110,14 @@ func (suite *Model9) Test0904_GetReceiptSuccessWithStatusTransactionStatus_SUCCE
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
 	)
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
-	ibtp = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
@@ -138,14 +139,14 @@ func (suite *Model9) Test0905_GetReceiptFailWithStatusTransactionStatus_FAILURE(
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
 	)
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
-	ibtp = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
@@ -167,7 +168,7 @@ func (suite *Model9) Test0906_GetReceiptAfterTimeOutWithStatusTransactionStatus_
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -180,7 +181,7 @@ func (suite *Model9) Test0906_GetReceiptAfterTimeOutWithStatusTransactionStatus_
 	status, err := suite.GetStatus(ibtp.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN_ROLLBACK, status)
-	ibtp = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_ROLLBACK, proof)
+	ibtp = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_ROLLBACK, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp, payload, proof)
 	suite.Require().Nil(err)
@@ -206,8 +207,8 @@ func (suite *Model9) Test0907_SendIBTPSWithStatusTransactionStatus_BEGIN() {
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -245,8 +246,8 @@ func (suite *Model9) Test0908_GetOneReceiptFailWithStatusTransactionStatus_BEGIN
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -264,7 +265,7 @@ func (suite *Model9) Test0908_GetOneReceiptFailWithStatusTransactionStatus_BEGIN
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
@@ -293,8 +294,8 @@ func (suite *Model9) Test0909_GetNoAllReceiptBeforeTimeOutWithStatusTransactionS
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -312,7 +313,7 @@ func (suite *Model9) Test0909_GetNoAllReceiptBeforeTimeOutWithStatusTransactionS
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
@@ -344,8 +345,8 @@ func (suite *Model9) Test0910_GetAllReceiptSuccessWithStatusTransactionStatus_SU
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -363,11 +364,11 @@ func (suite *Model9) Test0910_GetAllReceiptSuccessWithStatusTransactionStatus_SU
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
-	ibtp2 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_RECEIPT_SUCCESS, proof)
+	ibtp2 = suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_RECEIPT_SUCCESS, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp2, payload, proof)
 	suite.Require().Nil(err)
@@ -396,8 +397,8 @@ func (suite *Model9) Test0911_GetAllReceiptFailWithStatusTransactionStatus_FAILU
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -415,11 +416,11 @@ func (suite *Model9) Test0911_GetAllReceiptFailWithStatusTransactionStatus_FAILU
 	status, err = suite.GetStatus(ibtp2.ID())
 	suite.Require().Nil(err)
 	suite.Require().Equal(pb.TransactionStatus_BEGIN, status)
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
-	ibtp2 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_RECEIPT_FAILURE, proof)
+	ibtp2 = suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_RECEIPT_FAILURE, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp2, payload, proof)
 	suite.Require().Nil(err)
@@ -448,8 +449,8 @@ func (suite *Model9) Test0912_GetAllReceiptTimeOutWithStatusTransactionStatus_RO
 	box := packr.New(repo.ConfigPath, repo.ConfigPath)
 	proof, err := box.Find("proof_fabric")
 	suite.Require().Nil(err)
-	ibtp1 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
-	ibtp2 := suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_INTERCHAIN, proof)
+	ibtp1 := suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_INTERCHAIN, proof)
+	ibtp2 := suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_INTERCHAIN, proof)
 	payload := suite.MockContent(
 		"interchainCharge",
 		[][]byte{[]byte("Alice"), []byte("Alice"), []byte("1")},
@@ -470,11 +471,11 @@ func (suite *Model9) Test0912_GetAllReceiptTimeOutWithStatusTransactionStatus_RO
 	for i := 0; i < 15; i++ {
 		suite.SendTransaction(pk1)
 	}
-	ibtp1 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from2.String()+":mychannel&transfer", pb.IBTP_RECEIPT_ROLLBACK, proof)
+	ibtp1 = suite.MockIBTP(1, fullID(from1.String()), fullID(from2.String()), pb.IBTP_RECEIPT_ROLLBACK, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp1, payload, proof)
 	suite.Require().Nil(err)
-	ibtp2 = suite.MockIBTP(1, "1356:"+from1.String()+":mychannel&transfer", "1356:"+from3.String()+":mychannel&transfer", pb.IBTP_RECEIPT_ROLLBACK, proof)
+	ibtp2 = suite.MockIBTP(1, fullID(from1.String()), fullID(from3.String()), pb.IBTP_RECEIPT_ROLLBACK, proof)
 	payload = suite.MockResult([][]byte(nil))
 	err = suite.SendInterchainTx(pk1, ibtp2, payload, proof)
 	suite.Require().Nil(err)
@@ -488,20 +489,20 @@ func (suite *Model9) Test0912_GetAllReceiptTimeOutWithStatusTransactionStatus_RO
 
 // PrepareServer prepare a server and return privateKey
 func (suite *Snake) PrepareServer() (crypto.PrivateKey, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	from, err := pk.PublicKey().Address()
 	suite.Require().Nil(err)
 	err = suite.RegisterAppchainWithType(pk, "Fabric V1.4.3", HappyRuleAddr, "{\"channel_id\":\"mychannel\",\"chaincode_id\":\"broker\",\"broker_version\":\"1\"}")
 	suite.Require().Nil(err)
-	err = suite.RegisterServer(pk, from.String(), "mychannel&transfer", from.String(), "CallContract")
+	err = suite.RegisterServer(pk, from.String(), service.DefaultServiceID, from.String(), "CallContract")
 	suite.Require().Nil(err)
 	return pk, nil
 }
 
 // GetStatus get tx status
 func (suite *Model9) GetStatus(txId string) (pb.TransactionStatus, error) {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	client := suite.NewClient(pk)
 	tx, err := client.GenerateContractTx(pb.TransactionData_BVM, constant.TransactionMgrContractAddr.Address(), "GetStatus", rpcx.String(txId))
@@ -10,6 +10,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -580,6 +581,7 @@ func (suite *Snake) RegisterRole(id, typ, account string) error {
 	if err != nil {
 		return err
 	}
+	record(manifest.Role, id, nil)
 	return suite.VotePass(proposal)
 }
 
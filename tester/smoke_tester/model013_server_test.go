@@ -12,6 +12,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -383,6 +384,7 @@ func (suite *Snake) RegisterServer(pk crypto.PrivateKey, chainID, serviceID, nam
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Service, chainID+":"+serviceID, pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -503,7 +505,7 @@ func (suite *Model13) LogoutService(pk crypto.PrivateKey, chainServiceID string)
 
 // CheckServerStatus check server status
 func (suite *Snake) CheckServerStatus(serverID string, status governance.GovernanceStatus) error {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return err
 	}
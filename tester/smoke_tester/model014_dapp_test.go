@@ -12,6 +12,7 @@ import (
 	"github.com/meshplus/bitxhub-model/constant"
 	"github.com/meshplus/bitxhub-model/pb"
 	rpcx "github.com/meshplus/go-bitxhub-client"
+	"github.com/meshplus/premo/internal/manifest"
 	"github.com/meshplus/premo/internal/repo"
 )
 
@@ -49,7 +50,7 @@ func (suite *Model14) SetupTest() {
 //tc：根据存在的合约地址注册dapp，dapp注册成功
 func (suite *Model14) Test1401_RegisterDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -59,7 +60,7 @@ func (suite *Model14) Test1401_RegisterDappIsSuccess() {
 func (suite *Model14) Test1402_UpdateDappIsSuccess() {
 	address1 := suite.DeployLedgerContract()
 	address2 := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address1.String())
 	suite.Require().Nil(err)
@@ -74,7 +75,7 @@ func (suite *Model14) Test1402_UpdateDappIsSuccess() {
 //tc：根据存在的合约地址冻结dapp，dapp冻结成功
 func (suite *Model14) Test1403_FreezeDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -85,7 +86,7 @@ func (suite *Model14) Test1403_FreezeDappIsSuccess() {
 //tc：根据存在的合约地址激活dapp，dapp激活成功
 func (suite *Model14) Test1404_ActivateDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -100,11 +101,11 @@ func (suite *Model14) Test1404_ActivateDappIsSuccess() {
 //tc：根据存在的合约地址转让dapp，dapp转让成功
 func (suite *Model14) Test1405_TransferDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk1, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk1, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk1, address.String())
 	suite.Require().Nil(err)
-	pk2, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk2, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.TransferDapp(pk1, pk2)
 	suite.Require().Nil(err)
@@ -115,7 +116,7 @@ func (suite *Model14) Test1405_TransferDappIsSuccess() {
 //tc：评价存在dapp，dapp评价成功
 func (suite *Model14) Test1406_EvaluateDappIsSuccess() {
 	address := suite.DeployLedgerContract()
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	suite.Require().Nil(err)
 	err = suite.RegisterDapp(pk, address.String())
 	suite.Require().Nil(err)
@@ -142,6 +143,7 @@ func (suite *Snake) RegisterDapp(pk crypto.PrivateKey, conAddrs string) error {
 	if res.Status != pb.Receipt_SUCCESS {
 		return fmt.Errorf(string(res.Ret))
 	}
+	record(manifest.Dapp, suite.MockDappID(pk), pk)
 	result := &RegisterResult{}
 	err = json.Unmarshal(res.Ret, result)
 	if err != nil {
@@ -296,7 +298,7 @@ func (suite *Model14) EvaluateDapp(pk crypto.PrivateKey, id, desc string, score
 
 // CheckDappStatus check dapp status
 func (suite *Snake) CheckDappStatus(id string, status governance.GovernanceStatus) error {
-	pk, err := asym.GenerateKeyPair(crypto.Secp256k1)
+	pk, err := asym.GenerateKeyPair(repo.KeyType())
 	if err != nil {
 		return err
 	}